@@ -0,0 +1,145 @@
+package signedstrings
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"sync"
+)
+
+// Algorithm is a MAC or signature algorithm that can be used to sign strings.
+type Algorithm struct {
+	// Name identifies the algorithm inside signed strings, e.g. "HS512".
+	// Must be non-empty and consist of ASCII letters and digits.
+	Name string
+
+	// Hash is the hash function used with HMAC. Nil for asymmetric algorithms.
+	Hash func() hash.Hash
+
+	// Sign, Verify and Public implement asymmetric algorithms. Sign receives
+	// a private key from Configuration.Keys. Verify receives a public key,
+	// either from Configuration.PublicKeys or derived from a private key
+	// via Public.
+	Sign   func(key, message []byte) ([]byte, error)
+	Verify func(publicKey, message, sig []byte) bool
+	Public func(key []byte) ([]byte, error)
+
+	// FIPS marks algorithms approved by FIPS 140, the only ones allowed
+	// when Configuration.FIPS is set.
+	FIPS bool
+}
+
+// Built-in algorithms, registered by default.
+var (
+	HS256 = &Algorithm{Name: "HS256", Hash: sha256.New, FIPS: true}
+	HS384 = &Algorithm{Name: "HS384", Hash: sha512.New384, FIPS: true}
+	HS512 = &Algorithm{Name: "HS512", Hash: sha512.New, FIPS: true}
+
+	// Ed25519 uses 32-byte seeds or 64-byte private keys for signing and
+	// 32-byte public keys for verification.
+	Ed25519 = &Algorithm{
+		Name:   "Ed25519",
+		Sign:   ed25519Sign,
+		Verify: ed25519Verify,
+		Public: ed25519Public,
+		FIPS:   true,
+	}
+)
+
+// DefaultAlgorithm is used when Configuration.Algorithms is empty. Its strings
+// carry no algorithm tag, same as strings signed before tags were introduced.
+var DefaultAlgorithm = HS256
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = make(map[string]*Algorithm)
+)
+
+func init() {
+	RegisterAlgorithm(HS256)
+	RegisterAlgorithm(HS384)
+	RegisterAlgorithm(HS512)
+	RegisterAlgorithm(Ed25519)
+}
+
+// RegisterAlgorithm makes the given algorithm available for use in
+// Configuration.Algorithms. Panics if the name is invalid or already taken.
+func RegisterAlgorithm(alg *Algorithm) {
+	if !isValidAlgorithmName(alg.Name) {
+		panic("signedstrings: invalid algorithm name " + alg.Name)
+	}
+	if alg.Hash == nil && (alg.Sign == nil || alg.Verify == nil || alg.Public == nil) {
+		panic("signedstrings: algorithm " + alg.Name + " has neither hash nor signature functions")
+	}
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+	if algorithms[alg.Name] != nil {
+		panic("signedstrings: duplicate algorithm " + alg.Name)
+	}
+	algorithms[alg.Name] = alg
+}
+
+// LookupAlgorithm returns the registered algorithm with the given name,
+// or nil if there is none.
+func LookupAlgorithm(name string) *Algorithm {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	return algorithms[name]
+}
+
+func isValidAlgorithmName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAsymmetric returns whether the algorithm uses public key signatures.
+func (alg *Algorithm) IsAsymmetric() bool {
+	return alg.Hash == nil
+}
+
+func (alg *Algorithm) sum(message, key []byte) []byte {
+	h := hmac.New(alg.Hash, key)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func ed25519PrivateKey(key []byte) (ed25519.PrivateKey, error) {
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("signedstrings: invalid Ed25519 private key length %d", len(key))
+	}
+}
+
+func ed25519Sign(key, message []byte) ([]byte, error) {
+	priv, err := ed25519PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(priv, message), nil
+}
+
+func ed25519Verify(publicKey, message, sig []byte) bool {
+	return len(publicKey) == ed25519.PublicKeySize && ed25519.Verify(publicKey, message, sig)
+}
+
+func ed25519Public(key []byte) ([]byte, error) {
+	priv, err := ed25519PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return priv.Public().(ed25519.PublicKey), nil
+}
@@ -0,0 +1,129 @@
+package signedstrings_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func Example_algorithms() {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512", "HS256"},
+	}
+
+	signed := conf.Sign("foo")
+	print(signed[:10], nil)
+	print(conf.Validate(signed))
+	print(conf.Validate("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+
+	strict := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512"},
+	}
+	print(strict.Validate(signed))
+	print(strict.Validate("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+	print(strict.Validate("foo-HS384.4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"))
+	print(strict.Validate("foo-HS512."))
+
+	// Output: foo-HS512.
+	// foo
+	// foo
+	// foo
+	// err: unacceptable algorithm
+	// err: unacceptable algorithm
+	// err: invalid string
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	signedstrings.RegisterAlgorithm(&signedstrings.Algorithm{Name: "Test256", Hash: sha256.New})
+	if signedstrings.LookupAlgorithm("Test256") == nil {
+		t.Fatal("Test256 not registered")
+	}
+	assertPanic(t, "signedstrings: duplicate algorithm HS256", func() {
+		signedstrings.RegisterAlgorithm(&signedstrings.Algorithm{Name: "HS256", Hash: sha256.New})
+	})
+	assertPanic(t, "signedstrings: invalid algorithm name HS.256", func() {
+		signedstrings.RegisterAlgorithm(&signedstrings.Algorithm{Name: "HS.256", Hash: sha256.New})
+	})
+
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"Test256"},
+	}
+	signed := conf.Sign("foo")
+	if data, err := conf.Validate(signed); err != nil || data != "foo" {
+		t.Errorf("Validate(%q) = %q, %v", signed, data, err)
+	}
+}
+
+func TestSanityCheck_unknownAlgorithm(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS999"},
+	}
+	assertPanic(t, "signedstrings: unknown algorithm HS999", func() {
+		conf.Sign("foo")
+	})
+}
+
+func TestSanityCheck_dotSeparatorWithTags(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512"},
+		Sep:        ".",
+	}
+	assertPanic(t, "signedstrings: separator conflicts with algorithm tags", func() {
+		conf.Sign("foo")
+	})
+}
+
+func TestCheck_FIPS(t *testing.T) {
+	signedstrings.RegisterAlgorithm(&signedstrings.Algorithm{Name: "NotFIPS", Hash: sha256.New})
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512", "NotFIPS"},
+		FIPS:       true,
+	}
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: algorithm NotFIPS is not FIPS-approved" {
+		t.Errorf("Check() = %v", err)
+	}
+	conf.Algorithms = conf.Algorithms[:1]
+	if err := conf.Check(); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+}
+
+func Example_verifyOnly() {
+	issuer := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey}, // used as an Ed25519 seed
+		Algorithms: []string{"Ed25519"},
+	}
+	signed := issuer.Sign("foo")
+	fmt.Println(signed[:12], len(signed))
+
+	pub := must(signedstrings.Ed25519.Public(exampleKey))
+	verifier := signedstrings.Configuration{
+		PublicKeys: [][]byte{pub},
+		Algorithms: []string{"Ed25519"},
+	}
+	print(verifier.Validate(signed))
+	print(verifier.Validate(signed[:len(signed)-2] + "00"))
+	print(verifier.TrySign("bar"))
+
+	// Output: foo-Ed25519. 140
+	// foo
+	// err: invalid signature
+	// err: signedstrings: cannot sign with a verify-only configuration
+}
+
+func TestCheck_verifyOnlyHMAC(t *testing.T) {
+	conf := signedstrings.Configuration{
+		PublicKeys: [][]byte{exampleKey},
+	}
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: algorithm HS256 cannot be used with public keys only" {
+		t.Errorf("Check() = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package signedstrings_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestBodySigning(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	verifier := &signedstrings.BodyVerifier{Conf: conf}
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	send := func(r *http.Request) (int, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code, w.Body.String()
+	}
+
+	r := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":1}`))
+	if err := conf.SignBody(r); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Header.Get(signedstrings.ContentDigestHeader); got != "sha-256=:AVq9f1zFei3ZS3WQ8ErYCEJzkF7jPsXOvq5iJ2qX+GI=:" {
+		t.Errorf("Content-Digest = %q", got)
+	}
+	if code, body := send(r); code != 200 || body != `{"a":1}` {
+		t.Errorf("signed request: %d %q", code, body)
+	}
+
+	if code, _ := send(httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":1}`))); code != 401 {
+		t.Errorf("unsigned request: %d", code)
+	}
+
+	tampered := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":2}`))
+	tampered.Header = r.Header
+	if code, body := send(tampered); code != 401 {
+		t.Errorf("tampered request: %d %q", code, body)
+	}
+
+	forged := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":2}`))
+	if err := conf.SignBody(forged); err != nil {
+		t.Fatal(err)
+	}
+	forged.Header.Set(signedstrings.BodySignatureHeader, r.Header.Get(signedstrings.BodySignatureHeader))
+	if code, _ := send(forged); code != 401 {
+		t.Errorf("forged request: %d", code)
+	}
+
+	moved := httptest.NewRequest("DELETE", "/api/orders/1", strings.NewReader(`{"a":1}`))
+	moved.Header = r.Header
+	if code, _ := send(moved); code != 401 {
+		t.Errorf("request replayed to another endpoint: %d", code)
+	}
+}
+
+func TestBodySigning_freshness(t *testing.T) {
+	now := time.Now() // MemoryNonceStore expires nonces by the wall clock
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}, Now: func() time.Time { return now }}
+	verifier := &signedstrings.BodyVerifier{Conf: conf, Nonces: &signedstrings.MemoryNonceStore{}}
+	sign := func() *http.Request {
+		r := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":1}`))
+		if err := conf.SignBody(r); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	r := sign()
+	if err := verifier.Verify(r); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+	replay := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"a":1}`))
+	replay.Header = r.Header
+	if err := verifier.Verify(replay); err != signedstrings.Replayed {
+		t.Errorf("Verify(replayed) = %v, wanted Replayed", err)
+	}
+
+	r = sign()
+	now = now.Add(10 * time.Minute)
+	if err := verifier.Verify(r); err != signedstrings.StaleRequest {
+		t.Errorf("Verify(stale) = %v, wanted StaleRequest", err)
+	}
+}
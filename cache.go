@@ -0,0 +1,41 @@
+package signedstrings
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// SealCacheValue signs a value before it is stored in a shared cache, e.g.
+// memcached or Redis, binding it to its cache key. OpenCacheValue returns
+// the value only if it was sealed with the same keys for the same cache key,
+// so entries poisoned by another tenant or a bug, or moved between cache
+// keys, are never trusted. The result is the signature, a newline and value.
+func (conf *Configuration) SealCacheValue(key string, value []byte) ([]byte, error) {
+	auth, err := conf.signDetached(cacheData(key, value))
+	if err != nil {
+		return nil, err
+	}
+	sealed := make([]byte, 0, len(auth)+1+len(value))
+	sealed = append(sealed, auth...)
+	sealed = append(sealed, '\n')
+	return append(sealed, value...), nil
+}
+
+// OpenCacheValue verifies a value sealed by SealCacheValue for the same
+// cache key and returns the original value.
+func (conf *Configuration) OpenCacheValue(key string, sealed []byte) ([]byte, error) {
+	auth, value, ok := bytes.Cut(sealed, []byte{'\n'})
+	if !ok {
+		return nil, Invalid
+	}
+	if err := conf.validateDetached(cacheData(key, value), string(auth)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// cacheData length-prefixes the key, so that no key and value pair can be
+// confused with another.
+func cacheData(key string, value []byte) string {
+	return "cache " + strconv.Itoa(len(key)) + " " + key + string(value)
+}
@@ -0,0 +1,25 @@
+package signedstrings_test
+
+import (
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SealCacheValue() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	sealed := must(conf.SealCacheValue("user:42", []byte(`{"name":"Bob"}`)))
+	fmt.Printf("%s\n", sealed)
+
+	fmt.Printf("%s\n", must(conf.OpenCacheValue("user:42", sealed)))
+	print(conf.OpenCacheValue("user:43", sealed))
+	print(conf.OpenCacheValue("user:42", []byte(`{"name":"Bob","admin":true}`)))
+
+	// Output: 92c4543ba2974b86b0ce19c27179c9270d14847afa486b76a8a2d108a371b1b6
+	// {"name":"Bob"}
+	// {"name":"Bob"}
+	// err: invalid signature
+	// err: invalid string
+}
@@ -0,0 +1,161 @@
+package signedstrings
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnsatisfiedCaveat is the error returned for correctly signed strings
+// carrying a caveat that does not hold.
+var UnsatisfiedCaveat = errors.New("unsatisfied caveat")
+
+// caveatMark starts every caveat field, telling it apart from algorithm tags.
+const caveatMark = "~"
+
+// Attenuate adds a caveat to a signed string, restricting its use. Anyone
+// holding the string can add caveats without knowing the keys, but nobody
+// can remove them: the signature is replaced by an HMAC chained from the old
+// signature, macaroon-style. Validation enforces all caveats.
+//
+// Only the separator of the configuration is used, so a holder can call
+// this on a Configuration without keys. Asymmetric algorithms are not
+// supported.
+func (conf *Configuration) Attenuate(signed, caveat string) (string, error) {
+	if conf.QR {
+		return "", errors.New("signedstrings: QR mode does not support caveats")
+	} else if conf.FixedLength {
+		return "", errors.New("signedstrings: FixedLength does not support caveats")
+	} else if conf.SigFirst {
+		return "", errors.New("signedstrings: SigFirst does not support caveats")
+	}
+	sep := conf.sep()
+	if strings.Contains(sep, tagSep) || strings.Contains(sep, caveatMark) {
+		return "", errors.New("signedstrings: separator conflicts with caveats")
+	}
+	tok, ok := parseToken(signed, sep)
+	if !ok || tok.alg == nil {
+		return "", Invalid
+	} else if tok.alg.IsAsymmetric() {
+		return "", errors.New("signedstrings: caveats require an HMAC algorithm")
+	}
+	encs := conf.wellFormedEncodings(tok)
+	if len(encs) == 0 {
+		return "", Invalid
+	}
+	mac, err := encs[0].decode(tok.mac)
+	if err != nil {
+		return "", Invalid
+	}
+
+	field := caveatMark + escapeCaveat(caveat, sep)
+	mac = tok.alg.sum([]byte(field), mac)
+	head := signed[:len(signed)-len(tok.mac)]
+	return head + field + tagSep + encs[0].encode(mac), nil
+}
+
+// ValidateCaveats is like Validate, but accepts strings with caveats,
+// calling check for each one. A non-nil error from check rejects the string;
+// if it wraps Expired, an *ExpiredError carrying the data is returned.
+// StandardCaveats provides a check for CaveatExpires and CaveatScope.
+func (conf *Configuration) ValidateCaveats(signed string, check func(caveat string) error) (string, error) {
+	return conf.validate(context.Background(), signed, check)
+}
+
+func checkCaveats(caveats []string, check func(caveat string) error) error {
+	for _, field := range caveats {
+		caveat, ok := unescapeCaveat(field[len(caveatMark):])
+		if !ok {
+			return Invalid
+		}
+		if check == nil {
+			return fmt.Errorf("%w: %s", UnsatisfiedCaveat, caveat)
+		}
+		if err := check(caveat); err != nil {
+			return fmt.Errorf("%w: %s: %w", UnsatisfiedCaveat, caveat, err)
+		}
+	}
+	return nil
+}
+
+// CaveatExpires returns a caveat that makes a string expire at the given time.
+func CaveatExpires(t time.Time) string {
+	return "expires=" + strconv.FormatInt(t.Unix(), 10)
+}
+
+// CaveatScope returns a caveat limiting a string to the given scopes.
+// Multiple scope caveats intersect.
+func CaveatScope(scopes ...string) string {
+	return "scope=" + strings.Join(scopes, ",")
+}
+
+// StandardCaveats returns a check for ValidateCaveats that enforces
+// CaveatExpires against now and CaveatScope against the scope of the current
+// operation. Other caveats are rejected.
+func StandardCaveats(now time.Time, scope string) func(caveat string) error {
+	return func(caveat string) error {
+		key, value, _ := strings.Cut(caveat, "=")
+		switch key {
+		case "expires":
+			exp, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			} else if now.Unix() >= exp {
+				return Expired
+			}
+			return nil
+		case "scope":
+			for _, s := range strings.Split(value, ",") {
+				if s == scope {
+					return nil
+				}
+			}
+			return errors.New("out of scope")
+		default:
+			return errors.New("unknown caveat")
+		}
+	}
+}
+
+func isCaveatField(field string) bool {
+	return strings.HasPrefix(field, caveatMark)
+}
+
+// escapeCaveat percent-encodes everything but ASCII letters and digits,
+// and also any byte of the separator.
+func escapeCaveat(caveat, sep string) string {
+	var buf strings.Builder
+	for i := 0; i < len(caveat); i++ {
+		c := caveat[i]
+		if (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') && strings.IndexByte(sep, c) < 0 {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func unescapeCaveat(s string) (string, bool) {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			buf.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", false
+		}
+		b, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", false
+		}
+		buf.WriteByte(b[0])
+		i += 2
+	}
+	return buf.String(), true
+}
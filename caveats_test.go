@@ -0,0 +1,53 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_Attenuate() {
+	issuer := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-"},
+	}
+	signed := issuer.Sign("bob")
+
+	// the holder does not need keys to attenuate
+	holder := signedstrings.Configuration{}
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	signed = must(holder.Attenuate(signed, signedstrings.CaveatScope("read", "list")))
+	signed = must(holder.Attenuate(signed, signedstrings.CaveatExpires(expiry)))
+	fmt.Println(signed)
+
+	now := time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)
+	print(issuer.ValidateCaveats(signed, signedstrings.StandardCaveats(now, "read")))
+	print(issuer.ValidateCaveats(signed, signedstrings.StandardCaveats(now, "write")))
+	print(issuer.ValidateCaveats(signed, signedstrings.StandardCaveats(expiry, "read")))
+	print(issuer.Validate(signed))
+
+	// Output: TOKEN-bob-~scope%3Dread%2Clist.~expires%3D1893456000.f1c4a66252a9b5e6db5ff66d8901bd14708995d7972d427f7053c16271be9c60
+	// bob
+	// err: unsatisfied caveat: scope=read,list: out of scope
+	// err: unsatisfied caveat: expires=1893456000: expired
+	// err: unsatisfied caveat: scope=read,list
+}
+
+func TestAttenuate_cannotRemoveCaveats(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512"},
+	}
+	signed := must(conf.Attenuate(conf.Sign("foo"), "scope=read"))
+	if data, err := conf.ValidateCaveats(signed, func(string) error { return nil }); err != nil || data != "foo" {
+		t.Fatalf("ValidateCaveats(%q) = %q, %v", signed, data, err)
+	}
+
+	// dropping the caveat field without fixing up the MAC must fail
+	tampered := "foo-HS512." + signed[len(signed)-128:]
+	if _, err := conf.Validate(tampered); err != signedstrings.InvalidSig {
+		t.Errorf("Validate(%q) err = %v, wanted InvalidSig", tampered, err)
+	}
+}
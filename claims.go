@@ -0,0 +1,238 @@
+package signedstrings
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	// Expired is the error returned for correctly signed claims and strings
+	// that have expired. The returned error is an *ExpiredError carrying the
+	// payload.
+	Expired = errors.New("expired")
+	// InsufficientScope is the error returned for valid claims lacking a scope
+	// required by RequireScope or RequireAnyScope.
+	InsufficientScope = errors.New("insufficient scope")
+	// UnacceptableClaims is the error returned for valid claims rejected by
+	// RequireClaim or RejectUnknownClaims.
+	UnacceptableClaims = errors.New("unacceptable claims")
+)
+
+// ExpiredError is returned for correctly signed strings that have expired,
+// carrying the payload so that UIs can explain what has expired, e.g. offer
+// to send a fresh link to the same address. It matches Expired via errors.Is.
+// The payload is authentic, but must not be acted upon as if still valid.
+type ExpiredError struct {
+	Data string
+	err  error
+}
+
+func (e *ExpiredError) Error() string { return e.err.Error() }
+func (e *ExpiredError) Unwrap() error { return e.err }
+
+// Claims are the registered claims understood by VerifyClaims. Embed it into
+// your own claims struct to use them.
+type Claims struct {
+	// ExpiresAt is a Unix timestamp after which the claims are rejected.
+	// Zero means no expiration.
+	ExpiresAt int64 `json:"exp,omitempty"`
+
+	// Scope lists the permissions granted to the holder.
+	Scope []string `json:"scope,omitempty"`
+
+	raw    []byte // the encoded claims, for options that look beyond the above
+	target any    // the claims argument of VerifyClaims
+}
+
+// HasScope returns whether the given scope has been granted.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scope {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyOption adds a requirement to VerifyClaims.
+type VerifyOption func(c *Claims) error
+
+// RequireScope requires all of the given scopes to be granted.
+func RequireScope(scopes ...string) VerifyOption {
+	return func(c *Claims) error {
+		for _, s := range scopes {
+			if !c.HasScope(s) {
+				return fmt.Errorf("%w: missing %s", InsufficientScope, s)
+			}
+		}
+		return nil
+	}
+}
+
+// RequireAnyScope requires at least one of the given scopes to be granted.
+func RequireAnyScope(scopes ...string) VerifyOption {
+	return func(c *Claims) error {
+		for _, s := range scopes {
+			if c.HasScope(s) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: need any of %v", InsufficientScope, scopes)
+	}
+}
+
+// RequireClaim requires the named claim to equal value, compared after
+// a JSON round trip (so numbers match regardless of their Go type).
+func RequireClaim(name string, value any) VerifyOption {
+	want := mustJSONValue(value)
+	return func(c *Claims) error {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(c.raw, &fields); err != nil {
+			return err
+		}
+		var got any
+		if raw, ok := fields[name]; !ok || json.Unmarshal(raw, &got) != nil || !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("%w: %s", UnacceptableClaims, name)
+		}
+		return nil
+	}
+}
+
+// RejectUnknownClaims rejects claims carrying fields not defined by the
+// struct passed to VerifyClaims (or by Claims if none), catching tokens
+// issued for another purpose or by a newer issuer.
+func RejectUnknownClaims(c *Claims) error {
+	var v any = &Claims{}
+	if c.target != nil {
+		if t := reflect.TypeOf(c.target); t.Kind() == reflect.Pointer {
+			v = reflect.New(t.Elem()).Interface()
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(c.raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", UnacceptableClaims, err)
+	}
+	return nil
+}
+
+func mustJSONValue(value any) any {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// IssueClaims signs the JSON encoding of claims. The claims are readable by
+// anyone holding the string; they are protected from tampering, not disclosure.
+func (conf *Configuration) IssueClaims(claims any) (string, error) {
+	raw, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return conf.TrySign(base64.RawURLEncoding.EncodeToString(raw))
+}
+
+// ClaimsValidator can be implemented by claims structs to enforce domain
+// rules, e.g. that a plan is set. VerifyClaims calls Validate after all other
+// checks pass, and wraps the error it returns into UnacceptableClaims.
+type ClaimsValidator interface {
+	Validate(ctx context.Context) error
+}
+
+// VerifyClaims validates a string produced by IssueClaims, decodes it into
+// claims (unless nil), checks expiration and enforces the given options.
+// Expired claims are still decoded, and an *ExpiredError is returned.
+// See ClaimsValidator for running domain checks.
+func (conf *Configuration) VerifyClaims(signed string, claims any, opts ...VerifyOption) error {
+	return conf.VerifyClaimsContext(context.Background(), signed, claims, opts...)
+}
+
+// VerifyClaimsContext is VerifyClaims passing ctx to ClaimsValidator and
+// ValidateContext.
+func (conf *Configuration) VerifyClaimsContext(ctx context.Context, signed string, claims any, opts ...VerifyOption) error {
+	data, err := conf.ValidateContext(ctx, signed)
+	if err != nil {
+		return err
+	}
+	std, raw, ok := decodeClaims(data)
+	if !ok {
+		return Invalid
+	}
+	if claims != nil {
+		if err := json.Unmarshal(raw, claims); err != nil {
+			return err
+		}
+	}
+	std.target = claims
+
+	if std.ExpiresAt != 0 && conf.now().Unix() >= std.ExpiresAt {
+		return &ExpiredError{data, Expired}
+	}
+	for _, opt := range opts {
+		if err := opt(std); err != nil {
+			return err
+		}
+	}
+	if v, ok := claims.(ClaimsValidator); ok {
+		if err := v.Validate(ctx); err != nil {
+			return fmt.Errorf("%w: %w", UnacceptableClaims, err)
+		}
+	}
+	return nil
+}
+
+// IssueMap is IssueClaims for quick tooling that does not want to define
+// a claims struct. A positive ttl sets the "exp" claim; the map is not modified.
+func (conf *Configuration) IssueMap(claims map[string]any, ttl time.Duration) (string, error) {
+	if ttl > 0 {
+		m := make(map[string]any, len(claims)+1)
+		for k, v := range claims {
+			m[k] = v
+		}
+		m["exp"] = conf.now().Add(ttl).Unix()
+		claims = m
+	}
+	return conf.IssueClaims(claims)
+}
+
+// VerifyMap is VerifyClaims decoding into a map. Numbers decode as float64,
+// as usual with encoding/json.
+func (conf *Configuration) VerifyMap(signed string, opts ...VerifyOption) (map[string]any, error) {
+	var claims map[string]any
+	if err := conf.VerifyClaims(signed, &claims, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeClaims(data string) (*Claims, []byte, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, nil, false
+	}
+	var std Claims
+	if err := json.Unmarshal(raw, &std); err != nil {
+		return nil, nil, false
+	}
+	std.raw = raw
+	return &std, raw, true
+}
+
+func (conf *Configuration) now() time.Time {
+	if conf.Now != nil {
+		return conf.Now()
+	}
+	return time.Now()
+}
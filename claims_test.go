@@ -0,0 +1,144 @@
+package signedstrings_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+type sessionClaims struct {
+	signedstrings.Claims
+	UserID string `json:"uid"`
+}
+
+func ExampleConfiguration_VerifyClaims() {
+	conf := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"SESS-"},
+		Now:      func() time.Time { return time.Unix(1700000000, 0) },
+	}
+
+	signed := must(conf.IssueClaims(&sessionClaims{
+		Claims: signedstrings.Claims{
+			ExpiresAt: 1700003600,
+			Scope:     []string{"read", "write"},
+		},
+		UserID: "bob",
+	}))
+	fmt.Println(signed)
+
+	var claims sessionClaims
+	err := conf.VerifyClaims(signed, &claims, signedstrings.RequireScope("read"))
+	fmt.Println(claims.UserID, claims.Scope, err)
+
+	fmt.Println(conf.VerifyClaims(signed, nil, signedstrings.RequireScope("read", "admin")))
+	fmt.Println(conf.VerifyClaims(signed, nil, signedstrings.RequireAnyScope("admin", "write")))
+
+	conf.Now = func() time.Time { return time.Unix(1700003600, 0) }
+	fmt.Println(conf.VerifyClaims(signed, nil))
+
+	// Output: SESS-eyJleHAiOjE3MDAwMDM2MDAsInNjb3BlIjpbInJlYWQiLCJ3cml0ZSJdLCJ1aWQiOiJib2IifQ-879130f469a9d49ac0ebc8fb97abf15926e796fafb9eebc501c27c4fe0dcfcc2
+	// bob [read write] <nil>
+	// insufficient scope: missing admin
+	// <nil>
+	// expired
+}
+
+func ExampleExpiredError() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return time.Unix(1700000000, 0) },
+	}
+
+	signed := must(conf.Attenuate(conf.Sign("bob@example.com"), signedstrings.CaveatExpires(time.Unix(1690000000, 0))))
+	_, err := conf.ValidateCaveats(signed, signedstrings.StandardCaveats(conf.Now(), ""))
+	fmt.Println(err)
+
+	var expired *signedstrings.ExpiredError
+	if errors.As(err, &expired) {
+		fmt.Println("link expired, request a new one for", expired.Data)
+	}
+	fmt.Println(errors.Is(err, signedstrings.Expired), errors.Is(err, signedstrings.UnsatisfiedCaveat))
+
+	var claims sessionClaims
+	err = conf.VerifyClaims(must(conf.IssueClaims(&sessionClaims{
+		Claims: signedstrings.Claims{ExpiresAt: 1690000000},
+		UserID: "bob",
+	})), &claims)
+	fmt.Println(err, errors.As(err, &expired), claims.UserID)
+
+	// Output: unsatisfied caveat: expires=1690000000: expired
+	// link expired, request a new one for bob@example.com
+	// true true
+	// expired true bob
+}
+
+func ExampleConfiguration_IssueMap() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	signed := must(conf.IssueMap(map[string]any{"uid": "bob", "admin": true}, time.Hour))
+
+	claims := must(conf.VerifyMap(signed))
+	fmt.Println(claims["uid"], claims["admin"], int64(claims["exp"].(float64)))
+
+	conf.Now = func() time.Time { return time.Unix(1700003600, 0) }
+	print(conf.VerifyMap(signed))
+
+	// Output: bob true 1700003600
+	// err: expired
+}
+
+func ExampleRequireClaim() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	signed := must(conf.IssueMap(map[string]any{"uid": "bob", "tenant": 42}, 0))
+
+	var claims sessionClaims
+	fmt.Println(conf.VerifyClaims(signed, &claims, signedstrings.RequireClaim("tenant", 42)))
+	fmt.Println(conf.VerifyClaims(signed, &claims, signedstrings.RequireClaim("tenant", 43)))
+	fmt.Println(conf.VerifyClaims(signed, &claims, signedstrings.RequireClaim("region", "eu")))
+
+	fmt.Println(conf.VerifyClaims(signed, &claims, signedstrings.RejectUnknownClaims))
+	fmt.Println(conf.VerifyClaims(must(conf.IssueMap(map[string]any{"uid": "bob"}, 0)), &claims, signedstrings.RejectUnknownClaims))
+	print(conf.VerifyMap(signed, signedstrings.RejectUnknownClaims))
+
+	// Output: <nil>
+	// unacceptable claims: tenant
+	// unacceptable claims: region
+	// unacceptable claims: json: unknown field "tenant"
+	// <nil>
+	// map[tenant:42 uid:bob]
+}
+
+type planClaims struct {
+	signedstrings.Claims
+	Plan string `json:"plan"`
+}
+
+func (c *planClaims) Validate(ctx context.Context) error {
+	if c.Plan == "" {
+		return errors.New("plan must be non-empty")
+	}
+	return nil
+}
+
+func ExampleClaimsValidator() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+
+	var claims planClaims
+	fmt.Println(conf.VerifyClaims(must(conf.IssueClaims(&planClaims{Plan: "pro"})), &claims), claims.Plan)
+
+	err := conf.VerifyClaims(must(conf.IssueClaims(&planClaims{})), &claims)
+	fmt.Println(err, errors.Is(err, signedstrings.UnacceptableClaims))
+
+	// Output: <nil> pro
+	// unacceptable claims: plan must be non-empty true
+}
@@ -0,0 +1,265 @@
+// Command signedstrings signs and validates strings and files from the shell.
+//
+// Keys are taken from -keys or the SIGNEDSTRINGS_KEYS environment variable,
+// in the same comma or space-separated hex format as signedstrings.ParseKeys.
+//
+// The rotate command maintains a key set file: it puts a new key first and
+// schedules the old ones for retirement, then prints the configuration in
+// the format of Configuration.Set, ready to deploy as a flag or variable.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+const usage = `usage: signedstrings <command> [flags] [args]
+
+commands:
+  keygen            print a new random key (-words for a paper backup)
+  sign DATA         sign a string
+  validate TOKEN    validate a signed string and print its data
+  sign-file FILE    write a detached signature of FILE (Ed25519 only)
+  pubkey            print the public key (Ed25519 only)
+  sign-env FILE     add a signature to a .env file
+  verify-env FILE   verify a signed .env file
+  rotate FILE       add a new key to a key set file and print the configuration
+  test-vectors      print JSON test vectors for other implementations
+
+Run signedstrings <command> -h for the flags of each command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("signedstrings "+cmd, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var err error
+	switch cmd {
+	case "keygen":
+		err = keygen(flags, args, stdout)
+	case "sign":
+		err = sign(flags, args, stdout)
+	case "validate":
+		err = validate(flags, args, stdout)
+	case "sign-file":
+		err = signFile(flags, args, stdout)
+	case "pubkey":
+		err = pubkey(flags, args, stdout)
+	case "sign-env":
+		err = signEnv(flags, args, stdout)
+	case "verify-env":
+		err = verifyEnv(flags, args, stdout)
+	case "rotate":
+		err = rotate(flags, args, stdout)
+	case "test-vectors":
+		err = testVectors(flags, args, stdout)
+	default:
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	} else if err != nil {
+		fmt.Fprintf(stderr, "signedstrings %s: %v\n", cmd, err)
+		return 1
+	}
+	return 0
+}
+
+func keygen(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	size := flags.Int("bytes", 64, "key length in bytes")
+	words := flags.Bool("words", false, "also print the key as words for paper backups")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *size < signedstrings.MinKeyLen {
+		return fmt.Errorf("keys must be at least %d bytes", signedstrings.MinKeyLen)
+	}
+	key := make([]byte, *size)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, hex.EncodeToString(key))
+	if *words {
+		fmt.Fprintln(stdout, signedstrings.EncodeKeyWords(key))
+	}
+	return nil
+}
+
+func sign(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	if err := parseConfigArgs(flags, args, 1, conf); err != nil {
+		return err
+	}
+	signed, err := conf.TrySign(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, signed)
+	return nil
+}
+
+func validate(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	if err := parseConfigArgs(flags, args, 1, conf); err != nil {
+		return err
+	}
+	data, err := conf.Validate(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, data)
+	return nil
+}
+
+func signFile(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	format := flags.String("format", "minisign", "signature format: minisign (writes FILE.minisig) or signify (writes FILE.sig)")
+	comment := flags.String("comment", "", "minisign trusted comment (default: timestamp and file name)")
+	if err := parseConfigArgs(flags, args, 1, conf); err != nil {
+		return err
+	}
+	fn := flags.Arg(0)
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+
+	var sig, sigFile string
+	switch *format {
+	case "minisign":
+		if *comment == "" {
+			*comment = fmt.Sprintf("timestamp:%d\tfile:%s", time.Now().Unix(), fn)
+		}
+		sig, err = conf.Minisign(data, *comment)
+		sigFile = fn + ".minisig"
+	case "signify":
+		sig, err = conf.Signify(data)
+		sigFile = fn + ".sig"
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sigFile, []byte(sig), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, sigFile)
+	return nil
+}
+
+func pubkey(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	if err := parseConfigArgs(flags, args, 0, conf); err != nil {
+		return err
+	}
+	pub, err := conf.MinisignPublicKey()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, pub)
+	return nil
+}
+
+func signEnv(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	if err := parseConfigArgs(flags, args, 1, conf); err != nil {
+		return err
+	}
+	fn := flags.Arg(0)
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	signed, err := conf.SignEnv(string(raw))
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(fn)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fn, []byte(signed), fi.Mode().Perm())
+}
+
+func verifyEnv(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	conf := configFlags(flags)
+	if err := parseConfigArgs(flags, args, 1, conf); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	vars, err := conf.VerifyEnv(string(raw))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "OK, %d variables\n", len(vars))
+	return nil
+}
+
+func testVectors(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	if err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+	return signedstrings.WriteTestVectors(stdout)
+}
+
+func configFlags(flags *flag.FlagSet) *signedstrings.Configuration {
+	conf := &signedstrings.Configuration{}
+	flags.Var(&conf.Keys, "keys", "hex-encoded keys, first one signs (default $SIGNEDSTRINGS_KEYS)")
+	flags.Func("prefixes", "comma-separated prefixes, first one is used for signing", func(s string) error {
+		conf.Prefixes = strings.Split(s, ",")
+		return nil
+	})
+	flags.StringVar(&conf.Sep, "sep", "", "separator between data and signature (default \"-\")")
+	flags.Func("alg", "comma-separated algorithms, first one is used for signing (default HS256)", func(s string) error {
+		conf.Algorithms = strings.Split(s, ",")
+		return nil
+	})
+	return conf
+}
+
+func parseArgs(flags *flag.FlagSet, args []string, n int) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != n {
+		return fmt.Errorf("expected %d argument(s), got %d", n, flags.NArg())
+	}
+	return nil
+}
+
+// parseConfigArgs is parseArgs for commands taking configFlags.
+func parseConfigArgs(flags *flag.FlagSet, args []string, n int, conf *signedstrings.Configuration) error {
+	if err := parseArgs(flags, args, n); err != nil {
+		return err
+	}
+	if s := os.Getenv("SIGNEDSTRINGS_KEYS"); s != "" && len(conf.Keys) == 0 {
+		if err := conf.Keys.Set(s); err != nil {
+			return fmt.Errorf("SIGNEDSTRINGS_KEYS: %w", err)
+		}
+	}
+	return conf.Check()
+}
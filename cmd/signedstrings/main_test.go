@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+const testKey = "d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2"
+
+func runCmd(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return stdout.String() + stderr.String(), code
+}
+
+func TestSignValidate(t *testing.T) {
+	out, code := runCmd(t, "sign", "-keys", testKey, "-prefixes", "TOKEN-", "foo")
+	if code != 0 || out != "TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39\n" {
+		t.Fatalf("sign = %d %q", code, out)
+	}
+	out, code = runCmd(t, "validate", "-keys", testKey, "-prefixes", "TOKEN-", strings.TrimSpace(out))
+	if code != 0 || out != "foo\n" {
+		t.Errorf("validate = %d %q", code, out)
+	}
+	out, code = runCmd(t, "validate", "-keys", testKey, "foo-1111")
+	if code != 1 || out != "signedstrings validate: invalid signature: malformed\n" {
+		t.Errorf("validate = %d %q", code, out)
+	}
+	out, code = runCmd(t, "sign", "foo")
+	if code != 1 || out != "signedstrings sign: signedstrings: not configured\n" {
+		t.Errorf("sign without keys = %d %q", code, out)
+	}
+}
+
+func TestKeygen(t *testing.T) {
+	out, code := runCmd(t, "keygen", "-bytes", "32")
+	if code != 0 || len(out) != 65 {
+		t.Errorf("keygen = %d %q", code, out)
+	}
+}
+
+func TestKeygenWords(t *testing.T) {
+	out, code := runCmd(t, "keygen", "-bytes", "32", "-words")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if code != 0 || len(lines) != 2 {
+		t.Fatalf("keygen -words = %d %q", code, out)
+	}
+	key, err := signedstrings.DecodeKeyWords(lines[1])
+	if err != nil || hex.EncodeToString(key) != lines[0] {
+		t.Errorf("DecodeKeyWords(%q) = %x, %v, wanted %s", lines[1], key, err, lines[0])
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "artifact.txt")
+	if err := os.WriteFile(fn, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code := runCmd(t, "sign-file", "-keys", testKey, "-alg", "Ed25519", "-comment", "file:hello.txt", fn)
+	if code != 0 || out != fn+".minisig\n" {
+		t.Fatalf("sign-file = %d %q", code, out)
+	}
+	sig, _ := os.ReadFile(fn + ".minisig")
+	if !strings.HasPrefix(string(sig), "untrusted comment: ") || !strings.Contains(string(sig), "\ntrusted comment: file:hello.txt\n") {
+		t.Errorf("signature = %q", sig)
+	}
+
+	out, code = runCmd(t, "pubkey", "-keys", testKey, "-alg", "Ed25519")
+	if code != 0 || !strings.HasPrefix(out, "untrusted comment: signedstrings public key\nRWT") {
+		t.Errorf("pubkey = %d %q", code, out)
+	}
+}
+
+func TestTestVectors(t *testing.T) {
+	out, code := runCmd(t, "test-vectors")
+	if code != 0 || !strings.HasPrefix(out, "[\n  {\n    \"description\": \"default algorithm, no prefix\",") {
+		t.Errorf("test-vectors = %d %q", code, out)
+	}
+}
+
+func TestSignEnv(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(fn, []byte("A=1\nB=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if out, code := runCmd(t, "sign-env", "-keys", testKey, fn); code != 0 || out != "" {
+		t.Fatalf("sign-env = %d %q", code, out)
+	}
+	if out, code := runCmd(t, "verify-env", "-keys", testKey, fn); code != 0 || out != "OK, 2 variables\n" {
+		t.Errorf("verify-env = %d %q", code, out)
+	}
+	raw, _ := os.ReadFile(fn)
+	os.WriteFile(fn, []byte(strings.Replace(string(raw), "B=2", "B=3", 1)), 0o600)
+	if out, code := runCmd(t, "verify-env", "-keys", testKey, fn); code != 1 || out != "signedstrings verify-env: invalid signature\n" {
+		t.Errorf("verify-env of a tampered file = %d %q", code, out)
+	}
+}
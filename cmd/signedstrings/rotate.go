@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// now is overridden in tests.
+var now = time.Now
+
+// keysetFile is the state file of the rotate command.
+type keysetFile struct {
+	Keys []keysetEntry `json:"keys"`
+}
+
+type keysetEntry struct {
+	Key         string     `json:"key"` // hex-encoded
+	CreatedAt   time.Time  `json:"created_at"`
+	AcceptUntil *time.Time `json:"accept_until,omitempty"`
+}
+
+func rotate(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	size := flags.Int("bytes", 64, "new key length in bytes")
+	grace := flags.Duration("accept-for", 30*24*time.Hour, "how long to keep accepting strings signed with the old keys")
+	if err := parseArgs(flags, args, 1); err != nil {
+		return err
+	}
+	if *size < signedstrings.MinKeyLen {
+		return fmt.Errorf("keys must be at least %d bytes", signedstrings.MinKeyLen)
+	}
+	fn := flags.Arg(0)
+
+	var ks keysetFile
+	raw, err := os.ReadFile(fn)
+	if err == nil {
+		if err := json.Unmarshal(raw, &ks); err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	key := make([]byte, *size)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	t := now().UTC().Truncate(time.Second)
+	deadline := t.Add(*grace)
+	keys := []keysetEntry{{Key: hex.EncodeToString(key), CreatedAt: t}}
+	for _, e := range ks.Keys {
+		if e.AcceptUntil == nil {
+			e.AcceptUntil = &deadline
+		} else if !e.AcceptUntil.After(t) {
+			continue // retired
+		}
+		keys = append(keys, e)
+	}
+	ks.Keys = keys
+
+	conf, err := ks.configuration()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	if err := writeFileAtomic(fn, ks); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, conf.String())
+	return nil
+}
+
+func (ks *keysetFile) configuration() (*signedstrings.Configuration, error) {
+	conf := &signedstrings.Configuration{}
+	for _, e := range ks.Keys {
+		key, err := hex.DecodeString(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		conf.Keys = append(conf.Keys, key)
+		if e.AcceptUntil != nil {
+			if conf.AcceptUntil == nil {
+				conf.AcceptUntil = make(map[string]time.Time)
+			}
+			conf.AcceptUntil[signedstrings.KeyID(key)] = *e.AcceptUntil
+		}
+	}
+	return conf, conf.Check()
+}
+
+// writeFileAtomic writes the JSON encoding of v readable by the owner only,
+// replacing the file at once so that readers never see a partial key set.
+func writeFileAtomic(fn string, v any) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(fn), filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fn)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestRotate(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := t0
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	fn := filepath.Join(t.TempDir(), "keys.json")
+	rotateConf := func() *signedstrings.Configuration {
+		t.Helper()
+		out, code := runCmd(t, "rotate", "-bytes", "32", "-accept-for", "24h", fn)
+		if code != 0 {
+			t.Fatalf("rotate = %d %q", code, out)
+		}
+		var conf signedstrings.Configuration
+		if err := conf.Set(strings.TrimSpace(out)); err != nil {
+			t.Fatalf("rotate printed %q: %v", out, err)
+		}
+		return &conf
+	}
+
+	first := rotateConf()
+	if len(first.Keys) != 1 || len(first.AcceptUntil) != 0 {
+		t.Fatalf("first rotation: %v", first)
+	}
+	old := first.Sign("foo")
+
+	clock = t0.Add(time.Hour)
+	second := rotateConf()
+	if len(second.Keys) != 2 || string(second.Keys[1]) != string(first.Keys[0]) {
+		t.Fatalf("second rotation: %v", second)
+	}
+	if got, want := second.AcceptUntil[signedstrings.KeyID(first.Keys[0])], clock.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("accept until %v, wanted %v", got, want)
+	}
+	second.Now = func() time.Time { return clock }
+	if _, err := second.Validate(old); err != nil {
+		t.Errorf("old string: %v", err)
+	}
+
+	clock = t0.Add(48 * time.Hour)
+	third := rotateConf()
+	if len(third.Keys) != 2 || string(third.Keys[1]) != string(second.Keys[0]) {
+		t.Errorf("third rotation did not drop the retired key: %v", third)
+	}
+
+	var ks keysetFile
+	raw, _ := os.ReadFile(fn)
+	if err := json.Unmarshal(raw, &ks); err != nil || len(ks.Keys) != 2 || !ks.Keys[0].CreatedAt.Equal(clock) {
+		t.Errorf("state file = %s, %v", raw, err)
+	}
+	if fi, err := os.Stat(fn); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("state file mode = %v, %v", fi.Mode(), err)
+	}
+}
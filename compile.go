@@ -0,0 +1,284 @@
+package signedstrings
+
+import (
+	"crypto/hmac"
+	"errors"
+	"hash"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// compiledState holds the resolved settings of a compiled configuration.
+type compiledState struct {
+	rawKeys  Keys
+	keys     []ExternalKey
+	algs     []*Algorithm
+	prefixes []string
+	sep      string
+	added    map[string]time.Time // key ID to when SetKeys or Rotate added it
+
+	prefixConfs  map[string]*Configuration // compiled, see PrefixKeys and PrefixPolicies
+	purposeConfs map[string]*Configuration // compiled, see Purposes
+}
+
+// Compile checks the configuration once and returns an immutable copy with
+// defaults resolved and HMAC key pads precomputed. Operations on the copy
+// skip the per-call checks, so prefer it on hot paths. The copy must not be
+// modified; changes to the original do not affect it.
+func (conf *Configuration) Compile() (*Configuration, error) {
+	if err := conf.Check(); err != nil {
+		return nil, err
+	}
+	c := conf.clone()
+	st, err := c.compile()
+	if err != nil {
+		return nil, err
+	}
+	c.state = new(atomic.Pointer[compiledState])
+	c.state.Store(st)
+	return c, nil
+}
+
+func (conf *Configuration) compile() (*compiledState, error) {
+	st := &compiledState{
+		rawKeys:  conf.Keys,
+		algs:     conf.algorithms(),
+		prefixes: append([]string(nil), conf.prefixes()...),
+		sep:      conf.sep(),
+	}
+	if len(conf.ExternalKeys) > 0 {
+		st.keys = conf.ExternalKeys
+	} else {
+		for _, key := range conf.Keys {
+			st.keys = append(st.keys, newPooledKey(key, st.algs))
+		}
+	}
+	for _, prefix := range conf.prefixes() {
+		if !conf.hasPrefixConf(prefix) {
+			continue
+		}
+		if st.prefixConfs == nil {
+			st.prefixConfs = make(map[string]*Configuration)
+		}
+		sub, err := conf.prefixConf(prefix).Compile()
+		if err != nil {
+			panic(err) // checked by the caller
+		}
+		st.prefixConfs[prefix] = sub
+	}
+	for name, p := range conf.Purposes {
+		if st.purposeConfs == nil {
+			st.purposeConfs = make(map[string]*Configuration)
+		}
+		sub, err := conf.purposeConf(name, p)
+		if err != nil {
+			return nil, err
+		}
+		if st.purposeConfs[name], err = sub.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// compiled returns the current state of a compiled configuration, or nil.
+func (conf *Configuration) compiled() *compiledState {
+	if conf.state == nil {
+		return nil
+	}
+	return conf.state.Load()
+}
+
+// SetKeys atomically replaces the keys of a compiled configuration (see
+// Compile), so that keys can be rotated at runtime while other goroutines
+// sign and validate. The first key signs, all are accepted. The Keys field
+// is not updated; use ActiveKeys to read the keys back. Panics if the
+// configuration is not compiled.
+func (conf *Configuration) SetKeys(keys Keys) error {
+	return conf.updateKeys(func(Keys) Keys { return keys })
+}
+
+// Rotate atomically makes newKey the signing key of a compiled configuration,
+// keeping the current keys accepted. See SetKeys.
+func (conf *Configuration) Rotate(newKey []byte) error {
+	return conf.updateKeys(func(old Keys) Keys {
+		return append(Keys{newKey}, old...)
+	})
+}
+
+func (conf *Configuration) updateKeys(f func(old Keys) Keys) error {
+	if conf.state == nil {
+		panic("signedstrings: cannot change keys of a configuration that is not compiled")
+	}
+	if len(conf.ExternalKeys) > 0 {
+		return errors.New("signedstrings: cannot set keys of a configuration using ExternalKeys")
+	}
+	for {
+		old := conf.state.Load()
+		updated := conf.derive(cloneKeys(f(old.rawKeys)), conf.Prefixes)
+		updated.PublicKeys = conf.PublicKeys
+		updated.PrefixKeys = conf.PrefixKeys
+		updated.PrefixPolicies = conf.PrefixPolicies
+		updated.Purposes = conf.Purposes
+		if err := updated.Check(); err != nil {
+			return err
+		}
+		st, err := updated.compile()
+		if err != nil {
+			return err
+		}
+		known := make(map[string]bool)
+		for _, key := range old.rawKeys {
+			known[KeyID(key)] = true
+		}
+		st.added = make(map[string]time.Time)
+		for _, key := range st.rawKeys {
+			id := KeyID(key)
+			if t, ok := old.added[id]; ok {
+				st.added[id] = t
+			} else if !known[id] {
+				st.added[id] = conf.now()
+			}
+		}
+		if conf.state.CompareAndSwap(old, st) {
+			if conf.KeysRotated != nil {
+				ids := make([]string, len(st.rawKeys))
+				for i, key := range st.rawKeys {
+					ids[i] = KeyID(key)
+				}
+				conf.KeysRotated(ids)
+			}
+			return nil
+		}
+	}
+}
+
+// ActiveKeys returns the keys currently in use: the ones set by SetKeys or
+// Rotate on compiled configurations, or Keys.
+func (conf *Configuration) ActiveKeys() Keys {
+	if st := conf.compiled(); st != nil {
+		return st.rawKeys
+	}
+	return conf.Keys
+}
+
+// derive returns a configuration with the given keys and prefixes, sharing
+// all other settings of conf except public keys and per-prefix and
+// per-purpose settings.
+func (conf *Configuration) derive(keys Keys, prefixes []string) *Configuration {
+	c := *conf
+	c.Keys, c.Prefixes = keys, prefixes
+	c.ExternalKeys, c.PublicKeys = nil, nil
+	c.PrefixKeys, c.PrefixPolicies, c.Purposes = nil, nil, nil
+	c.KeysRotated = nil
+	c.state = nil
+	return &c
+}
+
+// clone returns an uncompiled copy of conf with the active keys, sharing no
+// slices or maps with it.
+func (conf *Configuration) clone() *Configuration {
+	c := *conf
+	c.state = nil
+	c.Keys = cloneKeys(conf.ActiveKeys())
+	c.ExternalKeys = append([]ExternalKey(nil), conf.ExternalKeys...)
+	c.PublicKeys = cloneKeys(conf.PublicKeys)
+	c.Prefixes = append([]string(nil), conf.Prefixes...)
+	c.Seps = append([]string(nil), conf.Seps...)
+	c.Algorithms = append([]string(nil), conf.Algorithms...)
+	c.Versions = append([]int(nil), conf.Versions...)
+	c.Transforms = append([]func(string) (string, error)(nil), conf.Transforms...)
+	c.SigEncodings = append([]string(nil), conf.SigEncodings...)
+	if conf.PrefixKeys != nil {
+		c.PrefixKeys = make(map[string]Keys, len(conf.PrefixKeys))
+		for prefix, keys := range conf.PrefixKeys {
+			c.PrefixKeys[prefix] = cloneKeys(keys)
+		}
+	}
+	if conf.PrefixPolicies != nil {
+		c.PrefixPolicies = make(map[string]PrefixPolicy, len(conf.PrefixPolicies))
+		for prefix, p := range conf.PrefixPolicies {
+			p.Keys = cloneKeys(p.Keys)
+			p.Algorithms = append([]string(nil), p.Algorithms...)
+			p.SigEncodings = append([]string(nil), p.SigEncodings...)
+			c.PrefixPolicies[prefix] = p
+		}
+	}
+	c.Purposes = cloneMap(conf.Purposes)
+	c.AcceptUntil = cloneMap(conf.AcceptUntil)
+	c.KeyExpiry = cloneMap(conf.KeyExpiry)
+	return &c
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+func cloneKeys(keys Keys) Keys {
+	if keys == nil {
+		return nil
+	}
+	clone := make(Keys, len(keys))
+	for i, key := range keys {
+		clone[i] = append([]byte(nil), key...)
+	}
+	return clone
+}
+
+// pooledKey is an in-memory key that reuses HMAC states, whose Reset restores
+// the precomputed key pads instead of hashing the key again.
+type pooledKey struct {
+	key   rawKey
+	pools map[*Algorithm]*sync.Pool
+}
+
+func newPooledKey(key []byte, algs []*Algorithm) *pooledKey {
+	k := &pooledKey{key: key, pools: make(map[*Algorithm]*sync.Pool)}
+	for _, alg := range algs {
+		if alg.IsAsymmetric() {
+			continue
+		}
+		alg := alg
+		k.pools[alg] = &sync.Pool{New: func() any {
+			return hmac.New(alg.Hash, key)
+		}}
+	}
+	return k
+}
+
+func (k *pooledKey) MAC(alg *Algorithm, message []byte) ([]byte, error) {
+	pool := k.pools[alg]
+	if pool == nil {
+		return k.key.MAC(alg, message)
+	}
+	h := pool.Get().(hash.Hash)
+	h.Write(message)
+	sum := h.Sum(nil)
+	h.Reset()
+	pool.Put(h)
+	return sum, nil
+}
+
+func (k *pooledKey) macParts(alg *Algorithm, parts []string) []byte {
+	pool := k.pools[alg]
+	if pool == nil {
+		return k.key.macParts(alg, parts)
+	}
+	h := pool.Get().(hash.Hash)
+	for _, p := range parts {
+		io.WriteString(h, p)
+	}
+	sum := h.Sum(nil)
+	h.Reset()
+	pool.Put(h)
+	return sum
+}
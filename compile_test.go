@@ -0,0 +1,164 @@
+package signedstrings_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_Compile() {
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-"},
+	}
+	compiled := must(conf.Compile())
+
+	// changes to the original do not affect the compiled copy
+	conf.Prefixes[0] = "OTHER-"
+	conf.Keys = nil
+
+	fmt.Println(compiled.Sign("foo"))
+	print(compiled.Validate("TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"))
+	print(conf.Compile())
+
+	// Output: TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39
+	// foo
+	// err: signedstrings: not configured
+}
+
+func TestCompile_concurrent(t *testing.T) {
+	conf := must((&signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512", "HS256", "Ed25519"},
+	}).Compile())
+	legacy := "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				data := fmt.Sprint(i, j)
+				if v, err := conf.Validate(conf.Sign(data)); err != nil || v != data {
+					t.Errorf("Validate(Sign(%q)) = %q, %v", data, v, err)
+				}
+				if v, err := conf.Validate(legacy); err != nil || v != "foo" {
+					t.Errorf("Validate(legacy) = %q, %v", v, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func ExampleConfiguration_Rotate() {
+	conf := must((&signedstrings.Configuration{Keys: [][]byte{exampleKey}}).Compile())
+	old := conf.Sign("foo")
+
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	fmt.Println(conf.Rotate(newKey))
+	fmt.Println(signedstrings.KeyID(conf.ActiveKeys()[0]) == signedstrings.KeyID(newKey), len(conf.ActiveKeys()))
+	print(conf.Validate(old))
+	print(conf.Validate(conf.Sign("foo")))
+
+	fmt.Println(conf.SetKeys(signedstrings.Keys{newKey}))
+	print(conf.Validate(old))
+	fmt.Println(conf.SetKeys(signedstrings.Keys{newKey[:8]}))
+	fmt.Println(conf.SetKeys(nil))
+
+	// Output: <nil>
+	// true 2
+	// foo
+	// foo
+	// <nil>
+	// err: invalid signature
+	// signedstrings: short key
+	// signedstrings: not configured
+}
+
+func TestRotate_concurrent(t *testing.T) {
+	conf := must((&signedstrings.Configuration{Keys: [][]byte{exampleKey}}).Compile())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				key := make([]byte, 32)
+				rand.Read(key)
+				if err := conf.Rotate(key); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := conf.Validate(conf.Sign("foo")); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(conf.ActiveKeys()); n != 201 {
+		t.Errorf("%d keys after rotations, wanted 201", n)
+	}
+}
+
+func TestRotate_duringValidation(t *testing.T) {
+	newKey := make([]byte, 32)
+	rand.Read(newKey)
+	var conf *signedstrings.Configuration
+	var used string
+	conf = must((&signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		CheckData: func(data string) error {
+			return conf.Rotate(newKey) // shifts exampleKey to index 1
+		},
+		KeyUsed: func(id string, index int) { used = id },
+	}).Compile())
+
+	if _, err := conf.Validate(conf.Sign("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if want := signedstrings.KeyID(exampleKey); used != want {
+		t.Errorf("KeyUsed got %s, wanted %s", used, want)
+	}
+}
+
+func TestSetKeys_notCompiled(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	assertPanic(t, "signedstrings: cannot change keys of a configuration that is not compiled", func() {
+		conf.SetKeys(nil)
+	})
+}
+
+func TestCompile_copies(t *testing.T) {
+	conf := &signedstrings.Configuration{
+		Keys:        [][]byte{exampleKey},
+		Prefixes:    []string{"A-"},
+		Seps:        []string{"-", " "},
+		Timestamp:   true,
+		Environment: "prod",
+		PrefixPolicies: map[string]signedstrings.PrefixPolicy{
+			"A-": {Algorithms: []string{"HS256"}},
+		},
+	}
+	compiled := must(conf.Compile())
+	conf.Seps[0] = "+"
+	conf.PrefixPolicies["A-"].Algorithms[0] = "HS512"
+	conf.Environment = "dev"
+
+	if compiled.Seps[0] != "-" || compiled.PrefixPolicies["A-"].Algorithms[0] != "HS256" {
+		t.Errorf("compiled configuration shares slices with the original")
+	}
+	if !compiled.Timestamp || compiled.Environment != "prod" {
+		t.Errorf("Compile lost settings: Timestamp=%v, Environment=%q", compiled.Timestamp, compiled.Environment)
+	}
+}
@@ -0,0 +1,96 @@
+package signedstrings
+
+import (
+	"os"
+	"strings"
+)
+
+// configSignatureLabel follows the comment marker on the signature line of
+// config files with embedded signatures.
+const configSignatureLabel = " signature: "
+
+// SignConfigFile signs a configuration file for distribution. If comment is
+// empty, the signature is written to a detached fn.sig file and covers the
+// exact bytes of fn. Otherwise it is embedded into fn as a last line
+// starting with the comment marker of the file format, e.g. "#" for YAML and
+// TOML or "//" for JSONC:
+//
+//	# signature: 1c54...7a1e
+//
+// An embedded signature covers the rest of the file up to line endings and
+// trailing newlines, and replaces an existing one.
+func (conf *Configuration) SignConfigFile(fn, comment string) error {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	if comment == "" {
+		auth, err := conf.signDetached(string(raw))
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(fn+".sig", []byte(auth+"\n"), 0o644)
+	}
+
+	body, _ := cutConfigSignature(string(raw), comment)
+	body = canonicalDocument(body)
+	auth, err := conf.signDetached(body)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(fn)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fn, []byte(body+comment+configSignatureLabel+auth+"\n"), fi.Mode().Perm())
+}
+
+// VerifyConfigFile verifies a file signed by SignConfigFile with the same
+// comment marker, and returns its contents (without an embedded signature).
+func (conf *Configuration) VerifyConfigFile(fn, comment string) ([]byte, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	if comment == "" {
+		sig, err := os.ReadFile(fn + ".sig")
+		if err != nil {
+			return nil, err
+		}
+		if err := conf.validateDetached(string(raw), strings.TrimSpace(string(sig))); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+
+	body, auth := cutConfigSignature(string(raw), comment)
+	if auth == "" {
+		return nil, Invalid
+	}
+	body = canonicalDocument(body)
+	if err := conf.validateDetached(body, auth); err != nil {
+		return nil, err
+	}
+	return []byte(body), nil
+}
+
+// LoadConfigFile verifies a file like VerifyConfigFile, and only then passes
+// its contents to parse, e.g. a closure around yaml.Unmarshal, so that
+// parsers never see tampered input.
+func (conf *Configuration) LoadConfigFile(fn, comment string, parse func(data []byte) error) error {
+	data, err := conf.VerifyConfigFile(fn, comment)
+	if err != nil {
+		return err
+	}
+	return parse(data)
+}
+
+// cutConfigSignature splits off the last line if it is a signature comment.
+func cutConfigSignature(s, comment string) (body, auth string) {
+	trimmed := strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	i := strings.LastIndex(trimmed, "\n") + 1
+	if a, ok := strings.CutPrefix(trimmed[i:], comment+configSignatureLabel); ok {
+		return trimmed[:i], strings.TrimSpace(a)
+	}
+	return s, ""
+}
@@ -0,0 +1,73 @@
+package signedstrings_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_LoadConfigFile() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	dir := must(os.MkdirTemp("", "config"))
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "app.yaml")
+
+	os.WriteFile(fn, []byte("port: 8080\nhost: example.com\n"), 0o644)
+	if err := conf.SignConfigFile(fn, "#"); err != nil {
+		panic(err)
+	}
+	fmt.Print(string(must(os.ReadFile(fn))))
+
+	err := conf.LoadConfigFile(fn, "#", func(data []byte) error {
+		fmt.Printf("parsing %q\n", data)
+		return nil
+	})
+	fmt.Println(err)
+
+	raw := must(os.ReadFile(fn))
+	os.WriteFile(fn, []byte(strings.Replace(string(raw), "8080", "6666", 1)), 0o644)
+	fmt.Println(conf.LoadConfigFile(fn, "#", func(data []byte) error {
+		panic("parsed tampered data")
+	}))
+
+	// Output: port: 8080
+	// host: example.com
+	// # signature: c7ebc2100ccb3f297d93341d9b9fe110d2e29ec1526b6c8e76ddd6007ce97b4c
+	// parsing "port: 8080\nhost: example.com\n"
+	// <nil>
+	// invalid signature
+}
+
+func TestSignConfigFile_detached(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	fn := filepath.Join(t.TempDir(), "app.json")
+	os.WriteFile(fn, []byte(`{"port": 8080}`), 0o644)
+	if err := conf.SignConfigFile(fn, ""); err != nil {
+		t.Fatal(err)
+	}
+	var v struct{ Port int }
+	err := conf.LoadConfigFile(fn, "", func(data []byte) error {
+		return json.Unmarshal(data, &v)
+	})
+	if err != nil || v.Port != 8080 {
+		t.Errorf("LoadConfigFile = %v, port %d", err, v.Port)
+	}
+
+	os.WriteFile(fn, []byte(`{"port": 8080} `), 0o644)
+	if _, err := conf.VerifyConfigFile(fn, ""); err == nil {
+		t.Error("VerifyConfigFile accepted a modified file")
+	}
+	os.Remove(fn + ".sig")
+	if _, err := conf.VerifyConfigFile(fn, ""); !os.IsNotExist(err) {
+		t.Errorf("VerifyConfigFile without signature = %v", err)
+	}
+}
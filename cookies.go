@@ -0,0 +1,110 @@
+package signedstrings
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CookieMiddleware makes cookies tamper-proof without touching handler code.
+// It signs every Set-Cookie value written by downstream handlers, and
+// validates incoming cookies, handing only the valid ones to the handler,
+// with signatures stripped. Each signature covers the cookie name too, so
+// values cannot be moved between cookies. Cookies that fail to sign, e.g.
+// non-ASCII ones with ASCIIOnly or any once the key is past its KeyExpiry,
+// are dropped from the response.
+func (conf *Configuration) CookieMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		cookies := r.Cookies()
+		r.Header.Del("Cookie")
+		for _, c := range cookies {
+			if value, ok := conf.validateCookie(c.Name, c.Value); ok {
+				c.Value = value
+				r.AddCookie(c)
+			}
+		}
+
+		sw := &cookieSigningWriter{ResponseWriter: w, conf: conf}
+		next.ServeHTTP(sw, r)
+		sw.signCookies()
+	})
+}
+
+func (conf *Configuration) signCookie(name, value string) (string, error) {
+	return conf.TrySign(name + "=" + value)
+}
+
+func (conf *Configuration) validateCookie(name, signed string) (string, bool) {
+	data, err := conf.Validate(signed)
+	if err != nil {
+		return "", false
+	}
+	n, value, ok := strings.Cut(data, "=")
+	if !ok || n != name {
+		return "", false
+	}
+	return value, true
+}
+
+type cookieSigningWriter struct {
+	http.ResponseWriter
+	conf   *Configuration
+	signed bool
+}
+
+func (w *cookieSigningWriter) WriteHeader(code int) {
+	w.signCookies()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cookieSigningWriter) Write(b []byte) (int, error) {
+	w.signCookies()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cookieSigningWriter) Flush() {
+	w.signCookies()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap supports http.ResponseController.
+func (w *cookieSigningWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *cookieSigningWriter) signCookies() {
+	if w.signed {
+		return
+	}
+	w.signed = true
+	h := w.Header()
+	lines := h["Set-Cookie"][:0]
+	for _, line := range h["Set-Cookie"] {
+		pair, attrs, _ := strings.Cut(line, ";")
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		signed, err := w.conf.signCookie(name, value)
+		if err != nil {
+			continue
+		}
+		line = name + "=" + signed
+		if attrs != "" {
+			line += ";" + attrs
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		h["Set-Cookie"] = lines
+	} else {
+		h.Del("Set-Cookie")
+	}
+}
@@ -0,0 +1,46 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_CookieMiddleware() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	handler := conf.CookieMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, c := range r.Cookies() {
+			fmt.Printf("got %s=%s\n", c.Name, c.Value)
+		}
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: "bob", Path: "/", HttpOnly: true})
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	setCookie := w.Header().Get("Set-Cookie")
+	fmt.Println(setCookie)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Cookie", "user=user=bob-2aac88639b2abd1c1736670ae7601f6b42595fd95dec4276d80ee878942fb4c3; admin=user=bob-2aac88639b2abd1c1736670ae7601f6b42595fd95dec4276d80ee878942fb4c3; plain=yes")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Output: user=user=bob-2aac88639b2abd1c1736670ae7601f6b42595fd95dec4276d80ee878942fb4c3; Path=/; HttpOnly
+	// got user=bob
+}
+
+func TestCookieMiddleware_signingFails(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}, ASCIIOnly: true}
+	handler := conf.CookieMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: "bob"})
+		w.Header().Add("Set-Cookie", "name=Zoë")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Name != "user" {
+		t.Errorf("cookies = %v, wanted only the signed user cookie", cookies)
+	}
+}
@@ -0,0 +1,28 @@
+package signedstrings
+
+import "fmt"
+
+// Countersign validates a string signed by conf and adds a signature made by
+// the countersigner on top, e.g. a gateway attesting a token issued by
+// a service. The countersignature covers the entire original string,
+// including its signature, and uses the countersigner's prefix and separator.
+func (conf *Configuration) Countersign(signed string, countersigner *Configuration) (string, error) {
+	if _, err := conf.Validate(signed); err != nil {
+		return "", err
+	}
+	return countersigner.TrySign(signed)
+}
+
+// ValidateCountersigned validates a string signed by conf and then
+// countersigned by each of the given countersigners, in order. All signatures
+// must be present and valid. Returns the original data.
+func (conf *Configuration) ValidateCountersigned(signed string, countersigners ...*Configuration) (string, error) {
+	for i := len(countersigners) - 1; i >= 0; i-- {
+		var err error
+		signed, err = countersigners[i].Validate(signed)
+		if err != nil {
+			return "", fmt.Errorf("countersignature %d: %w", i+1, err)
+		}
+	}
+	return conf.Validate(signed)
+}
@@ -0,0 +1,36 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+var gatewayKey = must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+
+func ExampleConfiguration_Countersign() {
+	service := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-"},
+	}
+	gateway := &signedstrings.Configuration{
+		Keys:     [][]byte{gatewayKey},
+		Prefixes: []string{"GW-"},
+	}
+
+	signed := service.Sign("foo")
+	countersigned := must(service.Countersign(signed, gateway))
+	fmt.Println(countersigned)
+
+	print(service.ValidateCountersigned(countersigned, gateway))
+	print(service.ValidateCountersigned(signed, gateway))
+	print(service.ValidateCountersigned(countersigned))
+	print(service.Countersign("TOKEN-foo-1111111111111111111111111111111111111111111111111111111111111111", gateway))
+
+	// Output: GW-TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39-2f20b95aacb195bf75fe54ffc9537851950df2308267aa988e2c69c8534ab7cf
+	// foo
+	// err: countersignature 1: invalid string
+	// err: invalid string
+	// err: invalid signature
+}
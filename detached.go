@@ -0,0 +1,27 @@
+package signedstrings
+
+// signDetached signs data and returns just the signature part, for protocols
+// that transmit the data separately.
+func (conf *Configuration) signDetached(data string) (string, error) {
+	signed, err := conf.TrySign(data)
+	if err != nil {
+		return "", err
+	}
+	_, auth := conf.cutSig(signed)
+	return auth, nil
+}
+
+// validateDetached verifies a signature produced by signDetached.
+func (conf *Configuration) validateDetached(data, auth string) error {
+	if auth == "" || conf.containsSep(auth) {
+		return Invalid
+	}
+	var err error
+	for _, prefix := range conf.prefixes() {
+		_, err = conf.Validate(conf.joinSig(prefix+data, auth))
+		if err == nil || !isValidationError(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package signedstrings
+
+import "strings"
+
+// DocumentTrailer starts the signature line of documents signed with
+// SignDocument.
+const DocumentTrailer = "-----SIGNATURE: "
+
+// SignDocument signs a multi-line text document by appending a trailer line:
+//
+//	first line
+//	second line
+//	-----SIGNATURE: 1c54...7a1e
+//
+// The document is canonicalized first: CRLF line endings become LF, and
+// trailing newlines are collapsed into one. The canonical document is
+// returned, so that signing is idempotent up to the trailer.
+func (conf *Configuration) SignDocument(doc string) (string, error) {
+	doc = canonicalDocument(doc)
+	auth, err := conf.signDetached(doc)
+	if err != nil {
+		return "", err
+	}
+	return doc + DocumentTrailer + auth + "\n", nil
+}
+
+// ValidateDocument verifies a document produced by SignDocument, even if its
+// line endings or trailing newlines have been changed since, and returns
+// the canonical document without the trailer.
+func (conf *Configuration) ValidateDocument(signed string) (string, error) {
+	signed = strings.TrimRight(strings.ReplaceAll(signed, "\r\n", "\n"), "\n")
+	i := strings.LastIndex(signed, "\n"+DocumentTrailer)
+	if i < 0 && !strings.HasPrefix(signed, DocumentTrailer) {
+		return "", Invalid
+	}
+	doc := canonicalDocument(signed[:i+1])
+	auth := strings.TrimSpace(signed[i+1+len(DocumentTrailer):])
+	if err := conf.validateDetached(doc, auth); err != nil {
+		return "", err
+	}
+	return doc, nil
+}
+
+func canonicalDocument(doc string) string {
+	doc = strings.TrimRight(strings.ReplaceAll(doc, "\r\n", "\n"), "\n")
+	if doc == "" {
+		return ""
+	}
+	return doc + "\n"
+}
@@ -0,0 +1,28 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignDocument() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	signed := must(conf.SignDocument("first line\r\nsecond line\r\n\r\n"))
+	fmt.Print(signed)
+
+	doc, err := conf.ValidateDocument(strings.ReplaceAll(signed, "\n", "\r\n") + "\r\n")
+	fmt.Printf("%q %v\n", doc, err)
+	print(conf.ValidateDocument(strings.Replace(signed, "second", "2nd", 1)))
+	print(conf.ValidateDocument("first line\nsecond line\n"))
+
+	// Output: first line
+	// second line
+	// -----SIGNATURE: d1e11698bf730a246d2ee991d8f0e8e572a0db83f53d569aa95fd947eb949c2d
+	// "first line\nsecond line\n" <nil>
+	// err: invalid signature
+	// err: invalid string
+}
@@ -0,0 +1,101 @@
+package signedstrings
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// macEncoding encodes MACs and signatures in signed strings.
+type macEncoding struct {
+	name       string
+	alphabet   string
+	encodedLen func(n int) int
+	encode     func(b []byte) string
+	decode     func(s string) ([]byte, error)
+}
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var (
+	hexMAC    = &macEncoding{"hex", "0123456789abcdef", hex.EncodedLen, hex.EncodeToString, hex.DecodeString}
+	base64MAC = &macEncoding{"base64", "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", base64.RawURLEncoding.EncodedLen, base64.RawURLEncoding.EncodeToString, base64.RawURLEncoding.DecodeString}
+	qrMAC     = &macEncoding{"base32", "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", base32NoPad.EncodedLen, base32NoPad.EncodeToString, base32NoPad.DecodeString}
+)
+
+var (
+	hexMACs = []*macEncoding{hexMAC}
+	qrMACs  = []*macEncoding{qrMAC}
+)
+
+func lookupMACEncoding(name string) *macEncoding {
+	for _, enc := range []*macEncoding{hexMAC, base64MAC} {
+		if enc.name == name {
+			return enc
+		}
+	}
+	return nil
+}
+
+// macEncoding returns the encoding of new signatures.
+func (conf *Configuration) macEncoding() *macEncoding {
+	return conf.macEncodings()[0]
+}
+
+// macEncodings returns the encodings accepted when validating, see
+// SigEncodings.
+func (conf *Configuration) macEncodings() []*macEncoding {
+	if conf.QR {
+		return qrMACs
+	} else if len(conf.SigEncodings) == 0 {
+		return hexMACs
+	}
+	encs := make([]*macEncoding, 0, len(conf.SigEncodings))
+	for _, name := range conf.SigEncodings {
+		encs = append(encs, lookupMACEncoding(name))
+	}
+	return encs
+}
+
+// wellFormedEncodings returns the accepted encodings in which the signature
+// of tok is well-formed.
+func (conf *Configuration) wellFormedEncodings(tok token) []*macEncoding {
+	encs := conf.macEncodings()
+	if len(encs) == 1 && encs[0].wellFormed(tok.alg, tok.mac) {
+		return encs
+	}
+	var result []*macEncoding
+	for _, enc := range encs {
+		if enc.wellFormed(tok.alg, tok.mac) {
+			result = append(result, enc)
+		}
+	}
+	return result
+}
+
+// wellFormed cheaply checks that mac is encoded and has the right length for
+// alg, so that garbage is rejected without computing a MAC per key.
+func (enc *macEncoding) wellFormed(alg *Algorithm, mac string) bool {
+	if !alg.IsAsymmetric() && len(mac) != enc.encodedLen(alg.Hash().Size()) {
+		return false
+	}
+	for i := 0; i < len(mac); i++ {
+		if strings.IndexByte(enc.alphabet, mac[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// qrAlphanumeric is the character set of the QR code alphanumeric mode.
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+func isQRAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(qrAlphanumeric, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
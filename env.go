@@ -0,0 +1,107 @@
+package signedstrings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvSignaturePrefix starts the comment line that carries the signature of
+// an .env bundle, see SignEnv.
+const EnvSignaturePrefix = "# signature: "
+
+// SignEnv signs an .env bundle of KEY=VALUE lines, returning it with
+// a signature comment appended (replacing an existing one). The signature
+// covers the assignments; comments, blank lines, indentation and line endings
+// may change without breaking it.
+func (conf *Configuration) SignEnv(env string) (string, error) {
+	body, _ := cutEnvSignature(env)
+	auth, err := conf.signDetached(canonicalEnv(body))
+	if err != nil {
+		return "", err
+	}
+	body = strings.TrimRight(body, "\r\n")
+	if body != "" {
+		body += "\n"
+	}
+	return body + EnvSignaturePrefix + auth + "\n", nil
+}
+
+// VerifyEnv verifies an .env bundle produced by SignEnv and returns its
+// variables.
+func (conf *Configuration) VerifyEnv(env string) (map[string]string, error) {
+	body, auth := cutEnvSignature(env)
+	if auth == "" {
+		return nil, Invalid
+	}
+	canonical := canonicalEnv(body)
+	if err := conf.validateDetached(canonical, auth); err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for _, line := range strings.Split(canonical, "\n") {
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: invalid line %q", Invalid, line)
+		}
+		vars[name] = unquoteEnv(strings.TrimSpace(value))
+	}
+	return vars, nil
+}
+
+// LoadEnvFile verifies a signed .env file (see SignEnv) and sets its
+// variables in the process environment, without overriding variables that
+// are already set. Call it at startup and refuse to boot on error, so that
+// a tampered file on a shared host is never used.
+func (conf *Configuration) LoadEnvFile(fn string) error {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	vars, err := conf.VerifyEnv(string(raw))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	for name, value := range vars {
+		if _, set := os.LookupEnv(name); !set {
+			if err := os.Setenv(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cutEnvSignature splits off the last signature comment.
+func cutEnvSignature(env string) (body, auth string) {
+	lines := strings.Split(strings.ReplaceAll(env, "\r\n", "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if a, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), EnvSignaturePrefix); ok {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "\n"), strings.TrimSpace(a)
+		}
+	}
+	return env, ""
+}
+
+// canonicalEnv keeps the trimmed assignment lines of env.
+func canonicalEnv(env string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(env, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func unquoteEnv(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignEnv() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	signed := must(conf.SignEnv("# database\nDB_URL=postgres://db/app\nexport DEBUG='false'\n"))
+	fmt.Print(signed)
+
+	vars := must(conf.VerifyEnv(strings.Replace(signed, "# database", "# primary database", 1)))
+	fmt.Println(vars["DB_URL"], vars["DEBUG"])
+	print(conf.VerifyEnv(strings.Replace(signed, "app", "evil", 1)))
+	print(conf.VerifyEnv("DB_URL=postgres://db/app\n"))
+
+	// Output: # database
+	// DB_URL=postgres://db/app
+	// export DEBUG='false'
+	// # signature: 88bbc60ca8e6a07e6cdbbcd30133b37f69d7f2dbf2d0dc2e15fa6ed8bf2b95b7
+	// postgres://db/app false
+	// err: invalid signature
+	// err: invalid string
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	fn := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(fn, []byte(must(conf.SignEnv("SIGNEDSTRINGS_TEST_A=1\nSIGNEDSTRINGS_TEST_B=2\n"))), 0o600)
+	t.Setenv("SIGNEDSTRINGS_TEST_B", "preset")
+	if err := conf.LoadEnvFile(fn); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := os.Getenv("SIGNEDSTRINGS_TEST_A"), os.Getenv("SIGNEDSTRINGS_TEST_B"); a != "1" || b != "preset" {
+		t.Errorf("A=%q B=%q", a, b)
+	}
+	os.Unsetenv("SIGNEDSTRINGS_TEST_A")
+
+	os.WriteFile(fn, []byte("SIGNEDSTRINGS_TEST_A=1\n"), 0o600)
+	if err := conf.LoadEnvFile(fn); err == nil || err.Error() != fn+": invalid string" {
+		t.Errorf("LoadEnvFile = %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package signedstrings
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Replayed is the error returned for messages that have already been seen.
+var Replayed = errors.New("replayed message")
+
+// Envelope wraps a message bus payload (Kafka, NATS, etc) with a signature,
+// so that consumers can reject forged or replayed events. The signature
+// covers the topic, so a message cannot be replayed on another topic.
+type Envelope struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"ts"`
+	KeyID     string `json:"kid"`
+	Payload   []byte `json:"payload"`
+	Signature string `json:"sig"`
+}
+
+// Envelopes seals and opens Envelopes.
+type Envelopes struct {
+	Conf *Configuration
+
+	// MaxAge is the maximum age of an accepted message, also tolerated as
+	// clock skew into the future. Defaults to 5 minutes.
+	MaxAge time.Duration
+
+	// Nonces, if set, remembers message IDs to reject duplicates.
+	Nonces NonceStore
+}
+
+// Seal wraps the payload into a signed envelope for the given topic.
+func (e *Envelopes) Seal(topic string, payload []byte) (*Envelope, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	env := &Envelope{
+		ID:        hex.EncodeToString(id[:]),
+		Timestamp: e.Conf.now().Unix(),
+		KeyID:     e.Conf.forPrefix(e.Conf.prefixes()[0]).keyID(e.Conf.algorithms()[0], 0),
+		Payload:   payload,
+	}
+	sig, err := e.Conf.signDetached(env.signedData(topic))
+	if err != nil {
+		return nil, err
+	}
+	env.Signature = sig
+	return env, nil
+}
+
+// Open verifies the envelope received on the given topic and returns its
+// payload. Stale messages fail with StaleRequest, duplicates with Replayed.
+func (e *Envelopes) Open(topic string, env *Envelope) ([]byte, error) {
+	return e.OpenContext(context.Background(), topic, env)
+}
+
+// OpenContext is like Open, but passes ctx to Nonces if it implements
+// ContextNonceStore.
+func (e *Envelopes) OpenContext(ctx context.Context, topic string, env *Envelope) ([]byte, error) {
+	if env.ID == "" {
+		return nil, Invalid
+	}
+	if err := e.Conf.validateDetached(env.signedData(topic), env.Signature); err != nil {
+		return nil, err
+	}
+	t := time.Unix(env.Timestamp, 0)
+	if d := e.Conf.now().Sub(t); d > e.maxAge() || d < -e.maxAge() {
+		return nil, StaleRequest
+	}
+	if e.Nonces != nil {
+		if fresh, err := useNonce(ctx, e.Nonces, env.ID, t.Add(e.maxAge())); err != nil {
+			return nil, err
+		} else if !fresh {
+			return nil, Replayed
+		}
+	}
+	return env.Payload, nil
+}
+
+// Producer wraps a function publishing raw messages into one that publishes
+// JSON-encoded sealed envelopes.
+func (e *Envelopes) Producer(publish func(topic string, msg []byte) error) func(topic string, payload []byte) error {
+	return func(topic string, payload []byte) error {
+		env, err := e.Seal(topic, payload)
+		if err != nil {
+			return err
+		}
+		msg, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		return publish(topic, msg)
+	}
+}
+
+// Consumer wraps a message handler into one that accepts JSON-encoded
+// envelopes, and only calls handle with the payloads of those that Open
+// accepts. Other messages fail with the error from Open.
+func (e *Envelopes) Consumer(handle func(topic string, payload []byte) error) func(topic string, msg []byte) error {
+	return func(topic string, msg []byte) error {
+		var env Envelope
+		if err := json.Unmarshal(msg, &env); err != nil {
+			return Invalid
+		}
+		payload, err := e.Open(topic, &env)
+		if err != nil {
+			return err
+		}
+		return handle(topic, payload)
+	}
+}
+
+func (e *Envelopes) maxAge() time.Duration {
+	if e.MaxAge == 0 {
+		return 5 * time.Minute
+	}
+	return e.MaxAge
+}
+
+func (env *Envelope) signedData(topic string) string {
+	digest := sha256.Sum256(env.Payload)
+	return topic + "\n" + env.ID + "\n" + strconv.FormatInt(env.Timestamp, 10) + "\n" + hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,83 @@
+package signedstrings_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleEnvelopes() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	envelopes := &signedstrings.Envelopes{Conf: conf, Nonces: &signedstrings.MemoryNonceStore{}}
+
+	var published [][]byte
+	publish := envelopes.Producer(func(topic string, msg []byte) error {
+		published = append(published, msg)
+		return nil
+	})
+	consume := envelopes.Consumer(func(topic string, payload []byte) error {
+		fmt.Printf("%s: %s\n", topic, payload)
+		return nil
+	})
+
+	publish("orders", []byte(`{"order":42}`))
+	fmt.Println(consume("orders", published[0]))
+	fmt.Println(consume("orders", published[0]))
+	fmt.Println(consume("refunds", published[0]))
+
+	// Output: orders: {"order":42}
+	// <nil>
+	// replayed message
+	// invalid signature
+}
+
+func TestEnvelopes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	envelopes := &signedstrings.Envelopes{Conf: conf}
+
+	env, err := envelopes.Seal("events", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.KeyID != signedstrings.KeyID(exampleKey) || env.Timestamp != now.Unix() || len(env.ID) != 32 {
+		t.Errorf("Seal = %+v", env)
+	}
+	if payload, err := envelopes.Open("events", env); err != nil || string(payload) != "hello" {
+		t.Errorf("Open = %q, %v", payload, err)
+	}
+
+	forged := *env
+	forged.Payload = []byte("goodbye")
+	if _, err := envelopes.Open("events", &forged); err != signedstrings.InvalidSig {
+		t.Errorf("Open(forged) = %v", err)
+	}
+	forged = *env
+	forged.Timestamp++
+	if _, err := envelopes.Open("events", &forged); err != signedstrings.InvalidSig {
+		t.Errorf("Open(retimed) = %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := envelopes.Open("events", env); err != signedstrings.StaleRequest {
+		t.Errorf("Open(stale) = %v", err)
+	}
+
+	consume := envelopes.Consumer(func(string, []byte) error {
+		t.Error("handler called")
+		return nil
+	})
+	if err := consume("events", []byte("not json")); err != signedstrings.Invalid {
+		t.Errorf("consume(garbage) = %v", err)
+	}
+	msg, _ := json.Marshal(&signedstrings.Envelope{Payload: []byte("x")})
+	if err := consume("events", msg); err != signedstrings.Invalid {
+		t.Errorf("consume(unsigned) = %v", err)
+	}
+}
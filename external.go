@@ -0,0 +1,90 @@
+package signedstrings
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"strings"
+)
+
+// ExternalKey is a key that lives outside of the process, e.g. in an HSM or
+// a cloud KMS. The library sends the message out and receives the MAC back,
+// so the raw key bytes never enter process memory.
+//
+// An ExternalKey can implement KeyID() string to identify itself in
+// introspection results and telemetry.
+type ExternalKey interface {
+	// MAC computes the raw (unencoded) MAC of message using the given algorithm.
+	// For asymmetric algorithms, this is the signature.
+	MAC(alg *Algorithm, message []byte) ([]byte, error)
+}
+
+// ContextExternalKey is an ExternalKey that accepts a context, so that
+// calls to a network-backed KMS honor timeouts and cancellation.
+// SignContext and ValidateContext call MACContext instead of MAC.
+type ContextExternalKey interface {
+	ExternalKey
+	MACContext(ctx context.Context, alg *Algorithm, message []byte) ([]byte, error)
+}
+
+// rawKey adapts an in-memory key to ExternalKey.
+type rawKey []byte
+
+func (key rawKey) MAC(alg *Algorithm, message []byte) ([]byte, error) {
+	if alg.IsAsymmetric() {
+		return alg.Sign(key, message)
+	}
+	return alg.sum(message, key), nil
+}
+
+func (conf *Configuration) keys() []ExternalKey {
+	keys, _ := conf.keySnapshot()
+	return keys
+}
+
+// keySnapshot returns the keys together with the raw keys they were made
+// of, consistent even if SetKeys runs concurrently.
+func (conf *Configuration) keySnapshot() ([]ExternalKey, Keys) {
+	if st := conf.compiled(); st != nil {
+		return st.keys, st.rawKeys
+	}
+	if len(conf.ExternalKeys) > 0 {
+		return conf.ExternalKeys, nil
+	}
+	keys := make([]ExternalKey, len(conf.Keys))
+	for i, key := range conf.Keys {
+		keys[i] = rawKey(key)
+	}
+	return keys, conf.Keys
+}
+
+// partsMACer is implemented by in-memory keys, which compute MACs over
+// several parts without concatenating them first.
+type partsMACer interface {
+	macParts(alg *Algorithm, parts []string) []byte
+}
+
+// macParts computes the MAC of the concatenation of parts.
+func macParts(ctx context.Context, key ExternalKey, alg *Algorithm, parts []string) ([]byte, error) {
+	if k, ok := key.(partsMACer); ok && !alg.IsAsymmetric() {
+		return k.macParts(alg, parts), nil
+	}
+	return keyMAC(ctx, key, alg, []byte(strings.Join(parts, "")))
+}
+
+// keyMAC calls MACContext if key implements ContextExternalKey, and MAC
+// otherwise.
+func keyMAC(ctx context.Context, key ExternalKey, alg *Algorithm, message []byte) ([]byte, error) {
+	if k, ok := key.(ContextExternalKey); ok {
+		return k.MACContext(ctx, alg, message)
+	}
+	return key.MAC(alg, message)
+}
+
+func (key rawKey) macParts(alg *Algorithm, parts []string) []byte {
+	h := hmac.New(alg.Hash, key)
+	for _, p := range parts {
+		io.WriteString(h, p)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,101 @@
+package signedstrings_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// fakeHSM stands in for a hardware module holding the key.
+type fakeHSM struct {
+	key  []byte
+	down bool
+}
+
+func (hsm *fakeHSM) MAC(alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	if hsm.down {
+		return nil, errors.New("hsm unavailable")
+	}
+	h := hmac.New(alg.Hash, hsm.key)
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+func Example_externalKeys() {
+	conf := signedstrings.Configuration{
+		ExternalKeys: []signedstrings.ExternalKey{&fakeHSM{key: exampleKey}},
+		Prefixes:     []string{"TOKEN-"},
+	}
+
+	fmt.Println(conf.Sign("foo"))
+	print(conf.Validate("TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"))
+
+	// Output: TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39
+	// foo
+}
+
+func TestExternalKeys_failure(t *testing.T) {
+	conf := signedstrings.Configuration{
+		ExternalKeys: []signedstrings.ExternalKey{&fakeHSM{key: exampleKey, down: true}},
+	}
+	if _, err := conf.TrySign("foo"); err == nil || err.Error() != "hsm unavailable" {
+		t.Errorf("TrySign err = %v", err)
+	}
+	if _, err := conf.Validate("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"); err == nil || err.Error() != "hsm unavailable" {
+		t.Errorf("Validate err = %v", err)
+	}
+	assertPanic(t, "hsm unavailable", func() {
+		conf.Sign("foo")
+	})
+}
+
+func TestSanityCheck_keysAndExternalKeys(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys:         [][]byte{exampleKey},
+		ExternalKeys: []signedstrings.ExternalKey{&fakeHSM{key: exampleKey}},
+	}
+	assertPanic(t, "signedstrings: both Keys and ExternalKeys are set", func() {
+		conf.Sign("foo")
+	})
+}
+
+// remoteKMS is a fakeHSM reached over the network.
+type remoteKMS struct {
+	fakeHSM
+}
+
+func (kms *remoteKMS) MACContext(ctx context.Context, alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return kms.MAC(alg, message)
+}
+
+func TestExternalKeys_context(t *testing.T) {
+	conf := signedstrings.Configuration{
+		ExternalKeys: []signedstrings.ExternalKey{&remoteKMS{fakeHSM{key: exampleKey}}},
+	}
+	signed, err := conf.SignContext(context.Background(), "foo")
+	if err != nil || signed != "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334" {
+		t.Errorf("SignContext = %q, %v", signed, err)
+	}
+	if data, err := conf.ValidateContext(context.Background(), signed); err != nil || data != "foo" {
+		t.Errorf("ValidateContext = %q, %v", data, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := conf.SignContext(ctx, "foo"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SignContext err = %v", err)
+	}
+	if _, err := conf.ValidateContext(ctx, signed); !errors.Is(err, context.Canceled) {
+		t.Errorf("ValidateContext err = %v", err)
+	}
+	if data, err := conf.Validate(signed); err != nil || data != "foo" {
+		t.Errorf("Validate = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,87 @@
+package signedstrings
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StaleFlags is the error returned by FlagReceiver for correctly signed flag
+// sets that are older than the applied one or than MaxAge.
+var StaleFlags = errors.New("stale flags")
+
+// flagSet is the signed payload of SignFlags.
+type flagSet struct {
+	Version  uint64          `json:"v"`
+	IssuedAt int64           `json:"iat"`
+	Flags    json.RawMessage `json:"flags"`
+}
+
+// SignFlags signs a feature flag set for delivery to clients. Version must
+// increase with every change; clients apply only newer versions, see
+// FlagReceiver.
+func (conf *Configuration) SignFlags(version uint64, flags any) (string, error) {
+	raw, err := json.Marshal(flags)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(flagSet{version, conf.now().Unix(), raw})
+	if err != nil {
+		return "", err
+	}
+	return conf.TrySign(base64.RawURLEncoding.EncodeToString(payload))
+}
+
+// FlagReceiver validates flag sets pushed to a client, rejecting forged ones,
+// and stale ones that could roll back a flag, e.g. replayed old pushes.
+type FlagReceiver struct {
+	Conf *Configuration
+
+	// MaxAge, if set, rejects flag sets signed longer ago than that.
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	version uint64
+}
+
+// Apply validates a flag set produced by SignFlags, decodes it into flags
+// and returns its version. Flag sets not newer than the last applied one
+// are rejected with StaleFlags, and flags are left untouched.
+func (fr *FlagReceiver) Apply(signed string, flags any) (uint64, error) {
+	data, err := fr.Conf.Validate(signed)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return 0, Invalid
+	}
+	var fs flagSet
+	if err := json.Unmarshal(raw, &fs); err != nil {
+		return 0, Invalid
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fs.Version <= fr.version {
+		return 0, fmt.Errorf("%w: version %d, applied %d", StaleFlags, fs.Version, fr.version)
+	}
+	if fr.MaxAge > 0 && fr.Conf.now().Sub(time.Unix(fs.IssuedAt, 0)) > fr.MaxAge {
+		return 0, fmt.Errorf("%w: signed at %d", StaleFlags, fs.IssuedAt)
+	}
+	if err := json.Unmarshal(fs.Flags, flags); err != nil {
+		return 0, err
+	}
+	fr.version = fs.Version
+	return fs.Version, nil
+}
+
+// Version returns the version of the last applied flag set, or 0.
+func (fr *FlagReceiver) Version() uint64 {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.version
+}
@@ -0,0 +1,39 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleFlagReceiver() {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	v1 := must(conf.SignFlags(1, map[string]bool{"new-checkout": false}))
+	v2 := must(conf.SignFlags(2, map[string]bool{"new-checkout": true}))
+	v3 := must(conf.SignFlags(3, map[string]bool{}))
+
+	receiver := &signedstrings.FlagReceiver{Conf: conf, MaxAge: time.Hour}
+	var flags map[string]bool
+	print(receiver.Apply(v2, &flags))
+	fmt.Println(flags["new-checkout"])
+	print(receiver.Apply(v1, &flags)) // replayed old push
+	fmt.Println(flags["new-checkout"])
+	print(receiver.Apply(v2[:len(v2)-1]+"0", &flags))
+
+	now = now.Add(2 * time.Hour)
+	print(receiver.Apply(v3, &flags)) // delayed push
+	print(receiver.Apply(must(conf.SignFlags(4, nil)), &flags))
+
+	// Output: 2
+	// true
+	// err: stale flags: version 1, applied 2
+	// true
+	// err: invalid signature
+	// err: stale flags: signed at 1700000000
+	// 4
+}
@@ -0,0 +1,207 @@
+package signedstrings
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set parses a configuration from a single string, so that small tools can
+// be configured with one flag or environment variable:
+//
+//	keys=d850...81c2,65ce...213c&prefixes=TOKEN-&sep=.&alg=HS512,HS256&fips=1
+//
+// The format is a URL query: escape special characters (including + and
+// commas inside list items) with %XX. A string without = is a list of keys.
+// The parameters are:
+//
+//	keys, prefixes, seps, alg, versions, sig-encodings   comma-separated lists
+//	sep, environment                                     strings
+//	fixed-length, sig-first, fips, ascii-only, reject-empty, redact-errors,
+//	reject-expired-keys, qr, timestamp, salt             booleans
+//	max-age, key-expiry-warning                          durations like 1h30m
+//	accept-until, key-expiry                             key IDs and Unix times: 65ce...:1700000000
+//
+// Only the given parameters are changed; other fields, e.g. ExternalKeys or
+// hooks set in code, are kept. A compiled configuration must be compiled
+// again. Together with String, this makes *Configuration a flag.Value.
+func (conf *Configuration) Set(raw string) error {
+	c := conf.clone()
+	if !strings.Contains(raw, "=") {
+		raw = "keys=" + raw
+	}
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if err := c.setParam(name, value); err != nil {
+			return err
+		}
+	}
+	if err := c.Check(); err != nil {
+		return err
+	}
+	*conf = *c
+	return nil
+}
+
+func (c *Configuration) setParam(name, raw string) error {
+	v, err := url.QueryUnescape(raw)
+	if err != nil {
+		return err
+	}
+	list := func() []string {
+		items := strings.Split(raw, ",")
+		for i, item := range items {
+			items[i], _ = url.QueryUnescape(item) // cannot fail if raw unescapes
+		}
+		return items
+	}
+	switch name {
+	case "keys":
+		c.Keys, err = ParseKeys(v)
+	case "prefixes":
+		c.Prefixes = list()
+	case "sep":
+		c.Sep = v
+	case "seps":
+		c.Seps = list()
+	case "alg":
+		c.Algorithms = list()
+	case "versions":
+		c.Versions = nil
+		for _, item := range list() {
+			n, perr := strconv.Atoi(item)
+			if perr != nil {
+				return fmt.Errorf("invalid version %q", item)
+			}
+			c.Versions = append(c.Versions, n)
+		}
+	case "sig-encodings":
+		c.SigEncodings = list()
+	case "environment":
+		c.Environment = v
+	case "fixed-length":
+		c.FixedLength, err = strconv.ParseBool(v)
+	case "sig-first":
+		c.SigFirst, err = strconv.ParseBool(v)
+	case "fips":
+		c.FIPS, err = strconv.ParseBool(v)
+	case "ascii-only":
+		c.ASCIIOnly, err = strconv.ParseBool(v)
+	case "reject-empty":
+		c.RejectEmpty, err = strconv.ParseBool(v)
+	case "redact-errors":
+		c.RedactErrors, err = strconv.ParseBool(v)
+	case "reject-expired-keys":
+		c.RejectExpiredKeys, err = strconv.ParseBool(v)
+	case "qr":
+		c.QR, err = strconv.ParseBool(v)
+	case "timestamp":
+		c.Timestamp, err = strconv.ParseBool(v)
+	case "salt":
+		c.Salt, err = strconv.ParseBool(v)
+	case "max-age":
+		c.MaxAge, err = time.ParseDuration(v)
+	case "key-expiry-warning":
+		c.KeyExpiryWarning, err = time.ParseDuration(v)
+	case "accept-until":
+		c.AcceptUntil, err = parseKeyTimes(name, list())
+	case "key-expiry":
+		c.KeyExpiry, err = parseKeyTimes(name, list())
+	default:
+		return fmt.Errorf("unknown configuration parameter %q", name)
+	}
+	return err
+}
+
+// String returns the configuration in the format accepted by Set, including
+// the keys. Fields not supported by Set are omitted.
+func (conf *Configuration) String() string {
+	var q []string
+	escape := func(value string) string {
+		return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+	}
+	add := func(name, value string) {
+		q = append(q, name+"="+escape(value))
+	}
+	addList := func(name string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		escaped := make([]string, len(items))
+		for i, item := range items {
+			escaped[i] = escape(item)
+		}
+		q = append(q, name+"="+strings.Join(escaped, ","))
+	}
+	addBool := func(name string, value bool) {
+		if value {
+			add(name, "1")
+		}
+	}
+	if keys := conf.ActiveKeys(); len(keys) > 0 {
+		add("keys", strings.ReplaceAll(keys.String(), " ", ","))
+	}
+	addList("prefixes", conf.Prefixes)
+	if conf.Sep != "" {
+		add("sep", conf.Sep)
+	}
+	addList("seps", conf.Seps)
+	addList("alg", conf.Algorithms)
+	var versions []string
+	for _, v := range conf.Versions {
+		versions = append(versions, strconv.Itoa(v))
+	}
+	addList("versions", versions)
+	addList("sig-encodings", conf.SigEncodings)
+	if conf.Environment != "" {
+		add("environment", conf.Environment)
+	}
+	addBool("fixed-length", conf.FixedLength)
+	addBool("sig-first", conf.SigFirst)
+	addBool("fips", conf.FIPS)
+	addBool("ascii-only", conf.ASCIIOnly)
+	addBool("reject-empty", conf.RejectEmpty)
+	addBool("redact-errors", conf.RedactErrors)
+	addBool("reject-expired-keys", conf.RejectExpiredKeys)
+	addBool("qr", conf.QR)
+	addBool("timestamp", conf.Timestamp)
+	addBool("salt", conf.Salt)
+	if conf.MaxAge != 0 {
+		add("max-age", conf.MaxAge.String())
+	}
+	if conf.KeyExpiryWarning != 0 {
+		add("key-expiry-warning", conf.KeyExpiryWarning.String())
+	}
+	addList("accept-until", formatKeyTimes(conf.AcceptUntil))
+	addList("key-expiry", formatKeyTimes(conf.KeyExpiry))
+	return strings.Join(q, "&")
+}
+
+// parseKeyTimes parses key IDs with Unix times, see AcceptUntil.
+func parseKeyTimes(name string, items []string) (map[string]time.Time, error) {
+	m := make(map[string]time.Time)
+	for _, item := range items {
+		id, v, _ := strings.Cut(item, ":")
+		unix, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id == "" {
+			return nil, fmt.Errorf("invalid %s item %q", name, item)
+		}
+		m[id] = time.Unix(unix, 0)
+	}
+	return m, nil
+}
+
+func formatKeyTimes(m map[string]time.Time) []string {
+	items := make([]string, 0, len(m))
+	for id, t := range m {
+		items = append(items, id+":"+strconv.FormatInt(t.Unix(), 10))
+	}
+	sort.Strings(items)
+	return items
+}
@@ -0,0 +1,70 @@
+package signedstrings_test
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_Set() {
+	var conf signedstrings.Configuration
+	fs := flag.NewFlagSet("example", flag.ContinueOnError)
+	fs.Var(&conf, "signing", "signing configuration")
+	fs.Parse([]string{"-signing", "keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&prefixes=TOKEN-,OLD-&sep=%20::%20"})
+
+	fmt.Println(conf.Sign("foo"))
+	fmt.Println(conf.String())
+
+	// Output: TOKEN-foo :: 4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39
+	// keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&prefixes=TOKEN-,OLD-&sep=%20%3A%3A%20
+}
+
+func TestConfiguration_Set(t *testing.T) {
+	tests := []struct {
+		raw string
+		err string
+	}{
+		{"d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2", ""},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&alg=HS512&fips=true", ""},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&alg=HS999", "signedstrings: unknown algorithm HS999"},
+		{"keys=d850af43", "4-byte key is too short, need at least 32 bytes"},
+		{"prefixes=TOKEN-", "signedstrings: not configured"},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&color=red", `unknown configuration parameter "color"`},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&accept-until=a814acf20ffba3c8:1700000000,65ce:1800000000", ""},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&accept-until=a814acf20ffba3c8", `invalid accept-until item "a814acf20ffba3c8"`},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&prefixes=A%2CB-,C-&seps=%20,%3A&versions=1,2&timestamp=1&max-age=1h&environment=staging", ""},
+		{"keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&max-age=soon", `time: invalid duration "soon"`},
+	}
+	for _, tt := range tests {
+		var conf signedstrings.Configuration
+		err := conf.Set(tt.raw)
+		if msg := fmt.Sprint(err); tt.err == "" && err != nil || tt.err != "" && msg != tt.err {
+			t.Errorf("Set(%q) = %v, wanted %q", tt.raw, err, tt.err)
+		}
+		if err == nil {
+			var again signedstrings.Configuration
+			if err := again.Set(conf.String()); err != nil || again.String() != conf.String() {
+				t.Errorf("round trip of %q: %q, %v", tt.raw, again.String(), err)
+			}
+		}
+	}
+}
+
+func TestConfiguration_Set_keepsFields(t *testing.T) {
+	var normalized bool
+	conf := signedstrings.Configuration{
+		Prefixes:  []string{"TOKEN-"},
+		Normalize: func(s string) string { normalized = true; return s },
+	}
+	if err := conf.Set("d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2"); err != nil {
+		t.Fatal(err)
+	}
+	if s := conf.Sign("foo"); s[:6] != "TOKEN-" || !normalized {
+		t.Errorf("Sign = %q, normalized = %v", s, normalized)
+	}
+	if got := conf.String(); got != "keys=d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2&prefixes=TOKEN-" {
+		t.Errorf("String = %q", got)
+	}
+}
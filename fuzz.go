@@ -0,0 +1,105 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FuzzRoundTrip checks that data signed by conf validates back to the same
+// data, for use in a fuzz target over a custom configuration:
+//
+//	func FuzzTokens(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data string) {
+//			if err := conf.FuzzRoundTrip(data); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+// Data that conf refuses to sign (see ASCIIOnly) is not an error.
+func (conf *Configuration) FuzzRoundTrip(data string) error {
+	signed, err := conf.TrySign(data)
+	if err != nil {
+		return nil
+	}
+	want := data
+	if conf.Normalize != nil {
+		want = conf.Normalize(data)
+	}
+	v, err := conf.ValidateDetailed(signed)
+	if err != nil {
+		return fmt.Errorf("Validate(Sign(%q)) failed: %w", data, err)
+	} else if v.Data != want {
+		return fmt.Errorf("Validate(Sign(%q)) = %q", data, v.Data)
+	} else if v.Prefix != conf.prefixes()[0] {
+		return fmt.Errorf("Validate(Sign(%q)) matched prefix %q", data, v.Prefix)
+	}
+	return nil
+}
+
+// FuzzParse feeds arbitrary input to the parsers of conf, checking that they
+// do not panic and agree with each other, for use in a fuzz target like
+// FuzzRoundTrip. Most inputs are invalid, which is not an error.
+func (conf *Configuration) FuzzParse(signed string) error {
+	v, err := conf.ValidateDetailed(signed)
+	in, ierr := conf.Inspect(signed)
+	if err != nil || conf.Normalize != nil {
+		return nil
+	}
+	if ierr != nil {
+		return fmt.Errorf("Inspect(%q) failed on a valid string: %w", signed, ierr)
+	} else if in.Data != v.Data || in.Prefix != v.Prefix || in.Algorithm != v.Algorithm {
+		return fmt.Errorf("Inspect(%q) = %+v, disagrees with %+v", signed, *in, v)
+	}
+	return nil
+}
+
+// WriteFuzzCorpus writes a seed corpus for FuzzParse targets into dir (e.g.
+// testdata/fuzz/FuzzTokens): strings signed by conf for each of the seeds,
+// and mutations of them that exercise rejection paths.
+func (conf *Configuration) WriteFuzzCorpus(dir string, seeds ...string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, input := range conf.fuzzInputs(seeds) {
+		content := "go test fuzz v1\nstring(" + strconv.Quote(input) + ")\n"
+		h := sha256.Sum256([]byte(content))
+		fn := filepath.Join(dir, hex.EncodeToString(h[:8]))
+		if err := os.WriteFile(fn, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conf *Configuration) fuzzInputs(seeds []string) []string {
+	sep := conf.sep()
+	inputs := []string{"", sep, sep + tagSep, sep + caveatMark}
+	for _, data := range seeds {
+		signed, err := conf.TrySign(data)
+		if err != nil {
+			continue
+		}
+		head, auth := conf.cutSig(signed)
+		inputs = append(inputs,
+			signed,
+			signed[:len(signed)-1],
+			signed+"0",
+			strings.ToUpper(signed),
+			head+sep+"HS999"+tagSep+auth,
+			head+sep+caveatMark+tagSep+auth,
+			head+sep+timestampMark+"1"+tagSep+auth,
+			head,
+			data,
+		)
+		if attenuated, err := conf.Attenuate(signed, CaveatScope("read")); err == nil {
+			inputs = append(inputs, attenuated)
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,54 @@
+package signedstrings_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+var fuzzConf = &signedstrings.Configuration{
+	Keys:       [][]byte{exampleKey},
+	Prefixes:   []string{"TOKEN-", "OLD-"},
+	Algorithms: []string{"HS512", "HS256"},
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("foo")
+	f.Add("")
+	f.Add("foo-bar.~baz")
+	f.Fuzz(func(t *testing.T, data string) {
+		if err := fuzzConf.FuzzRoundTrip(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("TOKEN-foo-HS512.")
+	f.Add("OLD-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39")
+	f.Fuzz(func(t *testing.T, signed string) {
+		if err := fuzzConf.FuzzParse(signed); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestWriteFuzzCorpus(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "FuzzParse")
+	if err := fuzzConf.WriteFuzzCorpus(dir, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) < 20 {
+		t.Fatalf("corpus has %d files, %v", len(entries), err)
+	}
+	for _, e := range entries {
+		raw, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		if !strings.HasPrefix(string(raw), "go test fuzz v1\nstring(\"") {
+			t.Errorf("%s = %q", e.Name(), raw)
+		}
+	}
+}
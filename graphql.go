@@ -0,0 +1,87 @@
+package signedstrings
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// anyVariables stands for the variables hash of approvals that allow any
+// variables, see SignPersistedQuery.
+const anyVariables = "*"
+
+// SignPersistedQuery approves a persisted GraphQL query, identified by its
+// hash (e.g. the sha256Hash of Apollo persisted queries), so that a gateway
+// can reject queries that were not registered by the build pipeline.
+// If variables is non-nil, the approval only holds for exactly those
+// variables; otherwise any variables are allowed.
+func (conf *Configuration) SignPersistedQuery(queryHash string, variables map[string]any) (string, error) {
+	vh := anyVariables
+	if variables != nil {
+		var err error
+		if vh, err = variablesHash(variables); err != nil {
+			return "", err
+		}
+	}
+	return conf.signDetached(persistedQueryData(queryHash, vh))
+}
+
+// VerifyPersistedQuery checks an approval made by SignPersistedQuery for
+// a query with the given hash and variables.
+func (conf *Configuration) VerifyPersistedQuery(queryHash string, variables map[string]any, sig string) error {
+	vh, err := variablesHash(variables)
+	if err != nil {
+		return Invalid
+	}
+	err = conf.validateDetached(persistedQueryData(queryHash, vh), sig)
+	if isValidationError(err) {
+		return conf.validateDetached(persistedQueryData(queryHash, anyVariables), sig)
+	}
+	return err
+}
+
+// VerifyPersistedQueryRequest is VerifyPersistedQuery for the JSON body of
+// a GraphQL request using the Apollo persisted query extension:
+//
+//	{"variables":{...},"extensions":{"persistedQuery":{"version":1,"sha256Hash":"..."}}}
+//
+// Numbers in variables are decoded as json.Number, keeping integers beyond
+// 2^53 exact, so they match approvals signed with int64 values.
+func (conf *Configuration) VerifyPersistedQueryRequest(body []byte, sig string) error {
+	var req struct {
+		Variables  map[string]any `json:"variables"`
+		Extensions struct {
+			PersistedQuery struct {
+				Hash string `json:"sha256Hash"`
+			} `json:"persistedQuery"`
+		} `json:"extensions"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil || req.Extensions.PersistedQuery.Hash == "" {
+		return Invalid
+	} else if _, err := dec.Token(); err != io.EOF {
+		return Invalid // trailing data
+	}
+	return conf.VerifyPersistedQuery(req.Extensions.PersistedQuery.Hash, req.Variables, sig)
+}
+
+func persistedQueryData(queryHash, variablesHash string) string {
+	return "graphql\n" + queryHash + "\n" + variablesHash
+}
+
+// variablesHash hashes the JSON encoding of variables, which is canonical:
+// encoding/json sorts map keys. Nil and empty variables hash the same.
+func variablesHash(variables map[string]any) (string, error) {
+	if variables == nil {
+		variables = map[string]any{}
+	}
+	raw, err := json.Marshal(variables)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(raw)
+	return hex.EncodeToString(h[:]), nil
+}
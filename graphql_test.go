@@ -0,0 +1,47 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignPersistedQuery() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	const hash = "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"
+
+	// in the build pipeline
+	anyVars := must(conf.SignPersistedQuery(hash, nil))
+	fixedVars := must(conf.SignPersistedQuery(hash, map[string]any{"id": 1, "lang": "en"}))
+
+	// in the gateway
+	fmt.Println(conf.VerifyPersistedQuery(hash, map[string]any{"id": 2}, anyVars))
+	fmt.Println(conf.VerifyPersistedQueryRequest([]byte(`{"variables":{"lang":"en","id":1},"extensions":{"persistedQuery":{"version":1,"sha256Hash":"`+hash+`"}}}`), fixedVars))
+	fmt.Println(conf.VerifyPersistedQuery(hash, map[string]any{"id": 2, "lang": "en"}, fixedVars))
+	fmt.Println(conf.VerifyPersistedQuery("0000"+hash[4:], nil, anyVars))
+
+	// Output: <nil>
+	// <nil>
+	// invalid signature
+	// invalid signature
+}
+
+func TestVerifyPersistedQueryRequest_largeNumbers(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	const hash = "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"
+	sig := must(conf.SignPersistedQuery(hash, map[string]any{"id": int64(9007199254740993)}))
+
+	request := func(id string) []byte {
+		return []byte(`{"variables":{"id":` + id + `},"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}}`)
+	}
+	if err := conf.VerifyPersistedQueryRequest(request("9007199254740993"), sig); err != nil {
+		t.Errorf("VerifyPersistedQueryRequest = %v", err)
+	}
+	if err := conf.VerifyPersistedQueryRequest(request("9007199254740992"), sig); err == nil {
+		t.Errorf("VerifyPersistedQueryRequest accepted a neighboring id")
+	}
+	if err := conf.VerifyPersistedQueryRequest(append(request("9007199254740993"), "{}"...), sig); err != signedstrings.Invalid {
+		t.Errorf("VerifyPersistedQueryRequest(trailing data) = %v", err)
+	}
+}
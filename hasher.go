@@ -0,0 +1,54 @@
+package signedstrings
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"sync/atomic"
+)
+
+// Hasher provides alternative implementations of hash functions, e.g.
+// a SIMD-accelerated SHA-256 for platforms where the standard library does
+// not use CPU hash extensions and validation dominates CPU profiles.
+type Hasher interface {
+	// Hash returns a constructor for the given hash function, or nil to keep
+	// the standard library implementation.
+	Hash(h crypto.Hash) func() hash.Hash
+}
+
+var customHasher atomic.Bool
+
+// UseHasher makes the built-in HMAC algorithms use hash implementations
+// provided by h; nil restores the standard library. Call it from init,
+// before any signing or validation.
+//
+// Third-party implementations are not FIPS-validated, so configurations
+// with FIPS set fail Check while a Hasher is in use.
+func UseHasher(h Hasher) {
+	std := map[*Algorithm]crypto.Hash{HS256: crypto.SHA256, HS384: crypto.SHA384, HS512: crypto.SHA512}
+	for alg, ch := range std {
+		var f func() hash.Hash
+		if h != nil {
+			f = h.Hash(ch)
+		}
+		if f == nil {
+			f = stdHash(ch)
+		}
+		alg.Hash = f
+	}
+	customHasher.Store(h != nil)
+}
+
+func stdHash(h crypto.Hash) func() hash.Hash {
+	switch h {
+	case crypto.SHA256:
+		return sha256.New
+	case crypto.SHA384:
+		return sha512.New384
+	case crypto.SHA512:
+		return sha512.New
+	default:
+		panic("unreachable")
+	}
+}
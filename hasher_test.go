@@ -0,0 +1,51 @@
+package signedstrings_test
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"hash"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+type countingHasher struct {
+	n int
+}
+
+func (h *countingHasher) Hash(ch crypto.Hash) func() hash.Hash {
+	if ch != crypto.SHA256 {
+		return nil
+	}
+	return func() hash.Hash {
+		h.n++
+		return sha256.New()
+	}
+}
+
+func TestUseHasher(t *testing.T) {
+	h := &countingHasher{}
+	signedstrings.UseHasher(h)
+	defer signedstrings.UseHasher(nil)
+
+	conf := signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	signed := conf.Sign("foo")
+	if signed != "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334" {
+		t.Errorf("Sign = %q", signed)
+	}
+	if h.n == 0 {
+		t.Error("custom hasher not used")
+	}
+
+	conf.FIPS = true
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: FIPS mode cannot use a custom Hasher" {
+		t.Errorf("Check() = %v", err)
+	}
+
+	signedstrings.UseHasher(nil)
+	n := h.n
+	conf.Sign("foo")
+	if h.n != n {
+		t.Error("custom hasher used after reset")
+	}
+}
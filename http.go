@@ -0,0 +1,69 @@
+package signedstrings
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Middleware returns HTTP middleware admitting requests that carry valid
+// claims (see IssueClaims) as a bearer token in the Authorization header and
+// satisfy the given options, e.g. RequireScope. Other requests get
+// 401 Unauthorized, or 403 Forbidden when the claims lack a required scope.
+// Handlers can get the claims via ClaimsFromContext[Claims].
+func (conf *Configuration) Middleware(opts ...VerifyOption) func(http.Handler) http.Handler {
+	return conf.middleware(func() any { return new(Claims) }, opts)
+}
+
+// ClaimsMiddleware is Configuration.Middleware decoding the claims into T,
+// so that handlers get them via ClaimsFromContext[T] and never touch tokens.
+func ClaimsMiddleware[T any](conf *Configuration, opts ...VerifyOption) func(http.Handler) http.Handler {
+	return conf.middleware(func() any { return new(T) }, opts)
+}
+
+// ClaimsFromContext returns the claims stored by Middleware or
+// ClaimsMiddleware[T].
+func ClaimsFromContext[T any](ctx context.Context) (*T, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*T)
+	return claims, ok
+}
+
+type claimsKey struct{}
+
+func (conf *Configuration) middleware(newClaims func() any, opts []VerifyOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing token", http.StatusUnauthorized)
+				return
+			}
+			claims := newClaims()
+			err := conf.VerifyClaimsContext(r.Context(), token, claims, opts...)
+			if errors.Is(err, InsufficientScope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+				http.Error(w, "insufficient scope", http.StatusForbidden)
+				return
+			} else if isValidationError(err) || errors.Is(err, UnacceptableClaims) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			} else if err != nil {
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
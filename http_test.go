@@ -0,0 +1,74 @@
+package signedstrings_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestMiddleware(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	handler := conf.Middleware(signedstrings.RequireScope("admin"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	admin := must(conf.IssueClaims(&signedstrings.Claims{Scope: []string{"admin"}}))
+	user := must(conf.IssueClaims(&signedstrings.Claims{Scope: []string{"read"}}))
+
+	tests := []struct {
+		auth string
+		code int
+	}{
+		{"", 401},
+		{"Basic Zm9vOmJhcg==", 401},
+		{"Bearer " + admin, 200},
+		{"bearer " + admin, 200},
+		{"Bearer " + user, 403},
+		{"Bearer " + admin[:len(admin)-1] + "0", 401},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("Authorization: %q: got %d, wanted %d", tt.auth, w.Code, tt.code)
+		}
+	}
+}
+
+func TestClaimsMiddleware(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	handler := signedstrings.ClaimsMiddleware[planClaims](conf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := signedstrings.ClaimsFromContext[planClaims](r.Context())
+		if !ok {
+			t.Fatal("no claims in context")
+		}
+		if _, ok := signedstrings.ClaimsFromContext[signedstrings.Claims](r.Context()); ok {
+			t.Error("claims of the wrong type found in context")
+		}
+		w.Write([]byte(claims.Plan))
+	}))
+
+	tests := []struct {
+		token string
+		code  int
+		body  string
+	}{
+		{must(conf.IssueClaims(&planClaims{Plan: "pro"})), 200, "pro"},
+		{must(conf.IssueClaims(&planClaims{})), 401, "invalid token\n"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, wanted %d %q", tt.token, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
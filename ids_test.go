@@ -0,0 +1,58 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleIDGenerator() {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	gen := &signedstrings.IDGenerator{Conf: conf, Node: 7}
+
+	id, external, _ := gen.NextExternal()
+	fmt.Println(id, external)
+	fmt.Println(gen.Parse(external))
+	fmt.Println(gen.Time(id).Format(time.RFC3339))
+	print(gen.Parse(external[:15] + "1" + external[16:]))
+	print(gen.Parse("foo"))
+
+	// Output: 187535720448028672 029a42be80007000c2a5b7d9f70e2f0f
+	// 187535720448028672 <nil>
+	// 2025-06-01T12:00:00Z
+	// err: invalid signature
+	// err: invalid string
+}
+
+func TestIDGenerator_monotonic(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	gen := &signedstrings.IDGenerator{Conf: conf, Node: signedstrings.MaxIDNode}
+	var prev int64
+	for i := 0; i < 10000; i++ {
+		if i == 5000 {
+			now = now.Add(-time.Second) // clock goes back
+		}
+		id, err := gen.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if id <= prev {
+			t.Fatalf("ID #%d = %d, not greater than %d", i, id, prev)
+		}
+		prev = id
+	}
+
+	gen = &signedstrings.IDGenerator{Conf: conf, Node: signedstrings.MaxIDNode + 1}
+	if _, err := gen.Next(); err == nil {
+		t.Error("Next succeeded with an out of range node")
+	}
+}
@@ -0,0 +1,64 @@
+package signedstrings
+
+import "encoding/hex"
+
+// indexLabel derives index keys from the configured keys, so that an index
+// never equals the signature of the same value.
+const indexLabel = "signedstrings index"
+
+// DeriveIndex returns a stable pseudonym of value: a hex-encoded HMAC
+// computed with a key derived from the first configured key. Store it next
+// to an encrypted column (a blind index) to look rows up by exact value
+// without storing the value in the clear. Panics if an external key fails.
+//
+// Indexes change when the signing key changes; see DeriveIndexes for lookups
+// during key rotation.
+func (conf *Configuration) DeriveIndex(value string) string {
+	return conf.deriveIndexes(value, 1)[0]
+}
+
+// DeriveIndexes returns the indexes of value under all configured keys,
+// the current one first, e.g. for a WHERE idx IN (...) lookup while rows
+// are being re-indexed with a new key.
+func (conf *Configuration) DeriveIndexes(value string) []string {
+	return conf.deriveIndexes(value, -1)
+}
+
+func (conf *Configuration) deriveIndexes(value string, limit int) []string {
+	if conf.Normalize != nil {
+		value = conf.Normalize(value)
+	}
+	return conf.keyedHashes(indexLabel, value, limit)
+}
+
+// keyedHashes returns hex-encoded HMACs of value computed with keys derived
+// from the first limit (or all, if negative) configured keys and label.
+func (conf *Configuration) keyedHashes(label, value string, limit int) []string {
+	macs := conf.keyedMACs(label, []byte(value), limit)
+	hashes := make([]string, len(macs))
+	for i, mac := range macs {
+		hashes[i] = hex.EncodeToString(mac)
+	}
+	return hashes
+}
+
+// keyedMACs is like keyedHashes, but returns raw MACs.
+func (conf *Configuration) keyedMACs(label string, value []byte, limit int) [][]byte {
+	conf.sanityCheck()
+	keys := conf.keys()
+	if len(keys) == 0 {
+		panic("signedstrings: keyed hashes require secret keys")
+	}
+	if limit > 0 && limit < len(keys) {
+		keys = keys[:limit]
+	}
+	macs := make([][]byte, len(keys))
+	for i, key := range keys {
+		derived, err := key.MAC(HS256, []byte(label))
+		if err != nil {
+			panic(err)
+		}
+		macs[i] = HS256.sum(value, derived)
+	}
+	return macs
+}
@@ -0,0 +1,24 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_DeriveIndex() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	old := conf.DeriveIndex("bob@example.com")
+	fmt.Println(old)
+
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	conf.Keys = [][]byte{newKey, exampleKey}
+	indexes := conf.DeriveIndexes("bob@example.com")
+	fmt.Println(len(indexes), indexes[0] == conf.DeriveIndex("bob@example.com"), indexes[1] == old)
+
+	// Output: a4e12768508e34762f762f80dec78c086254c0716ae908e0acc0c2d1cf4a0b36
+	// 2 true true
+}
@@ -0,0 +1,132 @@
+package signedstrings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Introspection is the response of IntrospectionHandler.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Payload   string `json:"payload,omitempty"`
+	KeyID     string `json:"key_id,omitempty"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+// Introspect validates a string and describes the result. Expired claims
+// (see IssueClaims) are reported as inactive. Errors other than validation
+// failures, e.g. from external keys, are returned.
+func (conf *Configuration) Introspect(signed string) (*Introspection, error) {
+	v, err := conf.validateToken(context.Background(), signed, nil)
+	if isValidationError(err) {
+		return &Introspection{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := &Introspection{
+		Active:  true,
+		Payload: v.data,
+		KeyID:   v.keyID,
+	}
+	if claims, _, ok := decodeClaims(v.data); ok && claims.ExpiresAt != 0 {
+		result.ExpiresAt = claims.ExpiresAt
+		if conf.now().Unix() >= claims.ExpiresAt {
+			return &Introspection{}, nil
+		}
+	}
+	return result, nil
+}
+
+// Inspection describes the structure of a string parsed by Inspect.
+type Inspection struct {
+	Prefix    string
+	Data      string
+	Version   int       // wire format version, see Configuration.Versions
+	Algorithm string    // empty if the tag names an unregistered algorithm
+	Caveats   []string  // see Attenuate
+	Signature string    // encoded, see SigEncodings
+	IssuedAt  time.Time // zero if the string carries no timestamp
+	Salt      string    // hex-encoded, see Configuration.Salt
+
+	// ExpiresAt is the earliest of CaveatExpires caveats and the exp claim
+	// (see IssueClaims), or zero if the string does not expire.
+	ExpiresAt time.Time
+}
+
+// Inspect parses a string WITHOUT VERIFYING THE SIGNATURE, for debugging
+// tools and admin UIs that display token contents. Anyone can forge a string
+// that inspects to anything; never make decisions based on the result,
+// use Validate or Introspect for that. Signed strings carry no key ID; see
+// ValidateDetailed to find out which key signed a valid string.
+func (conf *Configuration) Inspect(signed string) (*Inspection, error) {
+	tok, ok := conf.parse(signed)
+	if !ok {
+		return nil, Invalid
+	}
+	prefix, data, idx := conf.cutPrefix(tok.msg)
+	if idx < 0 {
+		return nil, Invalid
+	}
+
+	result := &Inspection{
+		Prefix:    prefix,
+		Data:      data,
+		Version:   tok.version,
+		Signature: tok.mac,
+		IssuedAt:  tok.issued,
+		Salt:      tok.salt,
+	}
+	if tok.alg != nil {
+		result.Algorithm = tok.alg.Name
+	}
+	expires := func(unix int64) {
+		if t := time.Unix(unix, 0); result.ExpiresAt.IsZero() || t.Before(result.ExpiresAt) {
+			result.ExpiresAt = t
+		}
+	}
+	for _, field := range tok.caveats {
+		caveat, ok := unescapeCaveat(field[len(caveatMark):])
+		if !ok {
+			return nil, Invalid
+		}
+		result.Caveats = append(result.Caveats, caveat)
+		if v, found := strings.CutPrefix(caveat, "expires="); found {
+			if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+				expires(unix)
+			}
+		}
+	}
+	if claims, _, ok := decodeClaims(data); ok && claims.ExpiresAt != 0 {
+		expires(claims.ExpiresAt)
+	}
+	return result, nil
+}
+
+// IntrospectionHandler returns a handler that lets services without access
+// to the keys ask whether a token is valid, similar to OAuth token
+// introspection (RFC 7662): POST the token in the "token" form field, get back
+// JSON {active, payload, key_id, expires_at}.
+//
+// The handler reveals payloads, so protect it like any internal endpoint.
+func (conf *Configuration) IntrospectionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		result, err := conf.Introspect(r.PostFormValue("token"))
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(result)
+	})
+}
@@ -0,0 +1,83 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_IntrospectionHandler() {
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	handler := conf.IntrospectionHandler()
+
+	introspect := func(token string) {
+		r := httptest.NewRequest("POST", "/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		fmt.Print(w.Code, " ", w.Body.String())
+	}
+
+	introspect(conf.Sign("foo"))
+	introspect(must(conf.IssueClaims(&signedstrings.Claims{ExpiresAt: 1700003600})))
+	introspect(must(conf.IssueClaims(&signedstrings.Claims{ExpiresAt: 1700000000})))
+	introspect("foo-1111")
+
+	// Output: 200 {"active":true,"payload":"foo","key_id":"a814acf20ffba3c8"}
+	// 200 {"active":true,"payload":"eyJleHAiOjE3MDAwMDM2MDB9","key_id":"a814acf20ffba3c8","expires_at":1700003600}
+	// 200 {"active":false}
+	// 200 {"active":false}
+}
+
+func TestIntrospect_maxAge(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Timestamp: true,
+		MaxAge:    time.Hour,
+		Now:       func() time.Time { return now },
+	}
+	signed := conf.Sign("foo")
+	now = now.Add(2 * time.Hour)
+
+	if result, err := conf.Introspect(signed); err != nil || result.Active {
+		t.Errorf("Introspect(expired) = %+v, %v", result, err)
+	}
+	r := httptest.NewRequest("POST", "/introspect", strings.NewReader(url.Values{"token": {signed}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	conf.IntrospectionHandler().ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != `{"active":false}`+"\n" {
+		t.Errorf("IntrospectionHandler(expired) = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func ExampleConfiguration_Inspect() {
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-"},
+	}
+	signed := must(conf.IssueClaims(&signedstrings.Claims{ExpiresAt: 1700003600}))
+	signed = must(conf.Attenuate(signed, signedstrings.CaveatExpires(time.Unix(1700000000, 0))))
+
+	// a verifier would reject this, but Inspect does not care
+	in := must(conf.Inspect(signed[:len(signed)-4] + "0000"))
+	fmt.Println(in.Prefix, in.Data, in.Algorithm)
+	fmt.Println(in.Caveats)
+	fmt.Println(in.Signature[len(in.Signature)-4:], in.ExpiresAt.Unix())
+
+	print(conf.Inspect("foo-1111"))
+
+	// Output: TOKEN- eyJleHAiOjE3MDAwMDM2MDB9 HS256
+	// [expires=1700000000]
+	// 0000 1700000000
+	// err: invalid string
+}
@@ -0,0 +1,70 @@
+package signedstrings
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// JSONConfiguration is used by SignedString values that have no Conf.
+// Set it at startup to sign and validate JSON fields without any glue.
+var JSONConfiguration *Configuration
+
+// SignedString is a value that appears in JSON as a signed string. Strings
+// are signed as is; other values are signed as base64-encoded JSON, readable
+// by anyone holding the string.
+//
+// Unmarshaling fails unless the signature is valid, so API structs can
+// embed signed IDs and tokens and rely on them being authentic.
+type SignedString[T any] struct {
+	Value T
+
+	// Conf defaults to JSONConfiguration.
+	Conf *Configuration
+}
+
+func (s SignedString[T]) MarshalJSON() ([]byte, error) {
+	var data string
+	if v, ok := any(s.Value).(string); ok {
+		data = v
+	} else {
+		raw, err := json.Marshal(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		data = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	signed, err := s.conf().TrySign(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(signed)
+}
+
+func (s *SignedString[T]) UnmarshalJSON(b []byte) error {
+	var signed string
+	if err := json.Unmarshal(b, &signed); err != nil {
+		return err
+	}
+	data, err := s.conf().Validate(signed)
+	if err != nil {
+		return err
+	}
+	if v, ok := any(&s.Value).(*string); ok {
+		*v = data
+		return nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return Invalid
+	}
+	return json.Unmarshal(raw, &s.Value)
+}
+
+func (s *SignedString[T]) conf() *Configuration {
+	if s.Conf != nil {
+		return s.Conf
+	} else if JSONConfiguration != nil {
+		return JSONConfiguration
+	}
+	panic("signedstrings: SignedString without Conf, and JSONConfiguration not set")
+}
@@ -0,0 +1,43 @@
+package signedstrings_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleSignedString() {
+	signedstrings.JSONConfiguration = &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	defer func() { signedstrings.JSONConfiguration = nil }()
+
+	type Cursor struct {
+		After int `json:"after"`
+	}
+	type Page struct {
+		Owner signedstrings.SignedString[string] `json:"owner"`
+		Next  signedstrings.SignedString[Cursor] `json:"next"`
+	}
+
+	b := must(json.Marshal(Page{
+		Owner: signedstrings.SignedString[string]{Value: "foo"},
+		Next:  signedstrings.SignedString[Cursor]{Value: Cursor{After: 42}},
+	}))
+	fmt.Println(string(b))
+
+	var page Page
+	fmt.Println(json.Unmarshal(b, &page), page.Owner.Value, page.Next.Value.After)
+	fmt.Println(json.Unmarshal([]byte(`{"owner":"bar-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"}`), &page))
+
+	// Output: {"owner":"foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334","next":"eyJhZnRlciI6NDJ9-aaa5eefecc773101cd51400e3289717c6d99b6cc3feab66753b36a03247baacf"}
+	// <nil> foo 42
+	// invalid signature
+}
+
+func TestSignedString_noConf(t *testing.T) {
+	var s signedstrings.SignedString[string]
+	assertPanic(t, "signedstrings: SignedString without Conf, and JSONConfiguration not set", func() {
+		s.MarshalJSON()
+	})
+}
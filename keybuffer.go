@@ -0,0 +1,74 @@
+package signedstrings
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KeyBuffer holds key material outside of ordinary garbage-collected memory,
+// e.g. in mlock'ed pages excluded from core dumps. NewLockedKey provides
+// a basic implementation; memguard's *LockedBuffer satisfies it as well.
+type KeyBuffer interface {
+	// Bytes returns the key. Callers must not modify or retain the slice.
+	Bytes() []byte
+
+	// Destroy wipes the key and releases the memory.
+	Destroy()
+}
+
+// BufferKey adapts a KeyBuffer for use in Configuration.ExternalKeys.
+// Buffers of NewLockedKey can be destroyed while in use, failing later MACs;
+// other buffers must only be destroyed once no longer used.
+func BufferKey(buf KeyBuffer) ExternalKey {
+	return bufferKey{buf}
+}
+
+type bufferKey struct {
+	buf KeyBuffer
+}
+
+// keyUser is implemented by the buffers of NewLockedKey, which keep the key
+// from being destroyed while f runs.
+type keyUser interface {
+	useKey(f func(key []byte))
+}
+
+func (k bufferKey) MAC(alg *Algorithm, message []byte) ([]byte, error) {
+	var mac []byte
+	var err error
+	compute := func(key []byte) {
+		if len(key) == 0 {
+			err = errors.New("signedstrings: key buffer destroyed")
+			return
+		}
+		mac, err = rawKey(key).MAC(alg, message)
+	}
+	if u, ok := k.buf.(keyUser); ok {
+		u.useKey(compute)
+	} else {
+		compute(k.buf.Bytes())
+	}
+	return mac, err
+}
+
+// NewLockedKey moves the key into memory that is locked against swapping
+// and, where supported, excluded from core dumps. The passed slice is wiped.
+// On platforms without mlock, the key is merely copied and wiped on Destroy.
+func NewLockedKey(key []byte) (KeyBuffer, error) {
+	if len(key) < MinKeyLen {
+		return nil, fmt.Errorf("%d-byte key is too short, need at least %d bytes", len(key), MinKeyLen)
+	}
+	buf, err := newLockedBuffer(len(key))
+	if err != nil {
+		return nil, err
+	}
+	copy(buf.Bytes(), key)
+	wipe(key)
+	return buf, nil
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
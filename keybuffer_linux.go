@@ -0,0 +1,10 @@
+package signedstrings
+
+import "syscall"
+
+// madvDontDump is MADV_DONTDUMP, missing from package syscall.
+const madvDontDump = 0x10
+
+func excludeFromDumps(mem []byte) {
+	syscall.Madvise(mem, madvDontDump)
+}
@@ -0,0 +1,5 @@
+//go:build unix && !linux
+
+package signedstrings
+
+func excludeFromDumps(mem []byte) {}
@@ -0,0 +1,33 @@
+//go:build !unix
+
+package signedstrings
+
+import "sync"
+
+type lockedBuffer struct {
+	mu  sync.RWMutex
+	key []byte
+}
+
+func newLockedBuffer(size int) (*lockedBuffer, error) {
+	return &lockedBuffer{key: make([]byte, size)}, nil
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.key
+}
+
+func (b *lockedBuffer) useKey(f func(key []byte)) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	f(b.key)
+}
+
+func (b *lockedBuffer) Destroy() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wipe(b.key)
+	b.key = nil
+}
@@ -0,0 +1,77 @@
+package signedstrings_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestNewLockedKey(t *testing.T) {
+	key := bytes.Clone(exampleKey)
+	buf, err := signedstrings.NewLockedKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), exampleKey) {
+		t.Errorf("Bytes() = %x, wanted %x", buf.Bytes(), exampleKey)
+	}
+	if !bytes.Equal(key, make([]byte, len(key))) {
+		t.Errorf("source key not wiped: %x", key)
+	}
+
+	conf := signedstrings.Configuration{
+		ExternalKeys: []signedstrings.ExternalKey{signedstrings.BufferKey(buf)},
+	}
+	signed := "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"
+	if s := conf.Sign("foo"); s != signed {
+		t.Errorf("Sign = %q, wanted %q", s, signed)
+	}
+
+	buf.Destroy()
+	buf.Destroy()
+	if _, err := conf.Validate(signed); err == nil {
+		t.Errorf("Validate succeeded after Destroy")
+	}
+}
+
+func TestNewLockedKey_short(t *testing.T) {
+	_, err := signedstrings.NewLockedKey([]byte{1, 2, 3})
+	if err == nil || err.Error() != "3-byte key is too short, need at least 32 bytes" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBufferKey_ed25519(t *testing.T) {
+	buf := must(signedstrings.NewLockedKey(bytes.Clone(exampleKey)))
+	defer buf.Destroy()
+	conf := signedstrings.Configuration{
+		ExternalKeys: []signedstrings.ExternalKey{signedstrings.BufferKey(buf)},
+		Algorithms:   []string{"Ed25519"},
+	}
+	plain := signedstrings.Configuration{Keys: [][]byte{exampleKey}, Algorithms: []string{"Ed25519"}}
+	if s, want := conf.Sign("foo"), plain.Sign("foo"); s != want {
+		t.Errorf("Sign = %q, wanted %q", s, want)
+	}
+}
+
+func TestBufferKey_concurrentDestroy(t *testing.T) {
+	buf := must(signedstrings.NewLockedKey(bytes.Clone(exampleKey)))
+	key := signedstrings.BufferKey(buf)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key.MAC(signedstrings.HS256, []byte("foo"))
+			}
+		}()
+	}
+	buf.Destroy()
+	wg.Wait()
+	if _, err := key.MAC(signedstrings.HS256, []byte("foo")); err == nil {
+		t.Error("MAC succeeded after Destroy")
+	}
+}
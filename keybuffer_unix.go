@@ -0,0 +1,54 @@
+//go:build unix
+
+package signedstrings
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+type lockedBuffer struct {
+	mu  sync.RWMutex // held for writing while unmapping
+	mem []byte
+	key []byte
+}
+
+func newLockedBuffer(size int) (*lockedBuffer, error) {
+	page := os.Getpagesize()
+	mem, err := syscall.Mmap(-1, 0, (size+page-1)/page*page, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("signedstrings: mmap: %w", err)
+	}
+	if err := syscall.Mlock(mem); err != nil {
+		syscall.Munmap(mem)
+		return nil, fmt.Errorf("signedstrings: mlock: %w", err)
+	}
+	excludeFromDumps(mem)
+	return &lockedBuffer{mem: mem, key: mem[:size]}, nil
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.key
+}
+
+func (b *lockedBuffer) useKey(f func(key []byte)) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	f(b.key)
+}
+
+func (b *lockedBuffer) Destroy() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mem == nil {
+		return
+	}
+	wipe(b.mem)
+	syscall.Munlock(b.mem)
+	syscall.Munmap(b.mem)
+	b.mem, b.key = nil, nil
+}
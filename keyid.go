@@ -0,0 +1,44 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// KeyID returns a short identifier of a key that is safe to log and publish:
+// the first 8 bytes of its SHA-256 hash, hex-encoded.
+func KeyID(key []byte) string {
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:8])
+}
+
+// keyID identifies the key at the given index of the current keys.
+// External keys can provide their own IDs by implementing KeyID() string,
+// otherwise their index is used.
+func (conf *Configuration) keyID(alg *Algorithm, index int) string {
+	if alg.IsAsymmetric() {
+		pubs, err := conf.publicKeys(alg)
+		if err != nil {
+			return strconv.Itoa(index)
+		}
+		return KeyID(pubs[index])
+	}
+	keys := conf.ActiveKeys()
+	if len(conf.ExternalKeys) == 0 && index >= len(keys) {
+		return strconv.Itoa(index) // rotated concurrently
+	}
+	return conf.hmacKeyID(keys, index)
+}
+
+// hmacKeyID identifies the HMAC key at the given index of keys, which must
+// come from the same snapshot as the key itself, see verify.
+func (conf *Configuration) hmacKeyID(keys Keys, index int) string {
+	if len(conf.ExternalKeys) > 0 {
+		if k, ok := conf.ExternalKeys[index].(interface{ KeyID() string }); ok {
+			return k.KeyID()
+		}
+		return strconv.Itoa(index)
+	}
+	return KeyID(keys[index])
+}
@@ -0,0 +1,87 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// KeySet describes the keys of a configuration for downstream verifiers,
+// similar to a JWK Set. See KeySetHandler.
+type KeySet struct {
+	Keys []PublishedKey `json:"keys"`
+}
+
+// PublishedKey is an entry of a KeySet.
+type PublishedKey struct {
+	KeyID     string `json:"kid"` // see KeyID
+	Algorithm string `json:"alg"`
+
+	// Key is the base64url-encoded public key of asymmetric algorithms.
+	// HMAC keys are secret, so their entries only announce the key ID.
+	Key string `json:"key,omitempty"`
+}
+
+// KeySet returns the published keys: for each accepted algorithm, every key
+// in rotation order, the signing key first.
+func (conf *Configuration) KeySet() (*KeySet, error) {
+	conf.sanityCheck()
+	ks := &KeySet{Keys: []PublishedKey{}}
+	for _, alg := range conf.algorithms() {
+		if !alg.IsAsymmetric() {
+			for i := range conf.keys() {
+				ks.Keys = append(ks.Keys, PublishedKey{KeyID: conf.keyID(alg, i), Algorithm: alg.Name})
+			}
+			continue
+		}
+		pubs, err := conf.publicKeys(alg)
+		if err != nil {
+			return nil, err
+		}
+		for _, pub := range pubs {
+			ks.Keys = append(ks.Keys, PublishedKey{
+				KeyID:     KeyID(pub),
+				Algorithm: alg.Name,
+				Key:       base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return ks, nil
+}
+
+// KeySetHandler returns a handler serving KeySet as JSON, so that verifiers
+// can bootstrap and refresh trust automatically (see RemoteKeySet). Responses
+// carry an ETag and can be cached for maxAge seconds.
+func (conf *Configuration) KeySetHandler(maxAge int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ks, err := conf.KeySet()
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		body, err := json.Marshal(ks)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		h := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(h[:8]) + `"`
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
@@ -0,0 +1,32 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_KeySetHandler() {
+	conf := &signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"Ed25519", "HS256"},
+	}
+	handler := conf.KeySetHandler(300)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/keys.json", nil))
+	etag := w.Header().Get("ETag")
+	fmt.Println(w.Code, w.Header().Get("Cache-Control"))
+	fmt.Println(w.Body.String())
+
+	r := httptest.NewRequest("GET", "/keys.json", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	fmt.Println(w.Code, w.Body.Len())
+
+	// Output: 200 public, max-age=300
+	// {"keys":[{"kid":"e2f143668939cd3e","alg":"Ed25519","key":"YC6nnrKfn-7rkAHFGPEGZCycgtBS8UKTLo55FRNVnhY"},{"kid":"a814acf20ffba3c8","alg":"HS256"}]}
+	// 304 0
+}
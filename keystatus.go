@@ -0,0 +1,100 @@
+package signedstrings
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// KeyStatus describes a key for operators, see KeyStatusHandler. Keys
+// themselves are never revealed, only their IDs.
+type KeyStatus struct {
+	KeyID   string `json:"kid"` // see KeyID
+	Signing bool   `json:"signing"`
+
+	// AddedAt is the Unix time when SetKeys or Rotate added the key to
+	// a compiled configuration in this process, zero if it was configured
+	// initially.
+	AddedAt    int64 `json:"added_at,omitempty"`
+	AgeSeconds int64 `json:"age_seconds,omitempty"`
+
+	AcceptUntil int64   `json:"accept_until,omitempty"` // Unix time, see Configuration.AcceptUntil
+	ExpiresAt   int64   `json:"expires_at,omitempty"`   // Unix time, see Configuration.KeyExpiry
+	Validations *uint64 `json:"validations,omitempty"`  // see KeyUsage
+}
+
+// KeyStatuses describes the keys of the configuration, the signing key
+// first. Usage is optional.
+func (conf *Configuration) KeyStatuses(usage *KeyUsage) []KeyStatus {
+	conf.sanityCheck()
+	var added map[string]time.Time
+	if st := conf.compiled(); st != nil {
+		added = st.added
+	}
+	var counts map[string]uint64
+	if usage != nil {
+		counts = usage.Counts()
+	}
+	alg := conf.algorithms()[0]
+	now := conf.now()
+	statuses := make([]KeyStatus, 0, len(conf.keys()))
+	for i := range conf.keys() {
+		id := conf.keyID(alg, i)
+		s := KeyStatus{KeyID: id, Signing: i == 0}
+		if t, ok := conf.AcceptUntil[id]; ok {
+			s.AcceptUntil = t.Unix()
+		}
+		if t, ok := conf.KeyExpiry[id]; ok {
+			s.ExpiresAt = t.Unix()
+		}
+		if t, ok := added[id]; ok {
+			s.AddedAt, s.AgeSeconds = t.Unix(), int64(now.Sub(t)/time.Second)
+		}
+		if counts != nil {
+			n := counts[id]
+			s.Validations = &n
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// KeyStatusHandler returns an admin handler serving KeyStatuses as JSON on
+// GET. If newKey is set and the configuration is compiled, POST rotates to
+// the key it returns (see Rotate) and responds with the new statuses.
+// Mount it behind the authentication of your admin endpoints.
+func (conf *Configuration) KeyStatusHandler(usage *KeyUsage, newKey func() ([]byte, error)) http.Handler {
+	canRotate := newKey != nil && conf.state != nil
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		case r.Method == http.MethodPost && canRotate:
+			key, err := newKey()
+			if err == nil {
+				err = conf.Rotate(key)
+			}
+			if err != nil {
+				http.Error(w, "rotation failed", http.StatusInternalServerError)
+				return
+			}
+		default:
+			if canRotate {
+				w.Header().Set("Allow", "GET, HEAD, POST")
+			} else {
+				w.Header().Set("Allow", "GET, HEAD")
+			}
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := json.Marshal(struct {
+			Keys []KeyStatus `json:"keys"`
+		}{conf.KeyStatuses(usage)})
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(body)
+	})
+}
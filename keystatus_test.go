@@ -0,0 +1,47 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_KeyStatusHandler() {
+	now := time.Unix(1700000000, 0)
+	var usage signedstrings.KeyUsage
+	conf := must((&signedstrings.Configuration{
+		Keys:    [][]byte{exampleKey},
+		KeyUsed: usage.Record,
+		Now:     func() time.Time { return now },
+	}).Compile())
+	newKey := func() ([]byte, error) {
+		return hex.DecodeString("283d54389c394ed33ba4146eff7b4133f7e393cb905d089a06798456a1cb7dcd")
+	}
+	handler := conf.KeyStatusHandler(&usage, newKey)
+	conf.Validate(conf.Sign("foo"))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/admin/keys", nil))
+	fmt.Println(w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/admin/keys", nil))
+	fmt.Println(w.Code, w.Body.String())
+
+	now = now.Add(time.Hour)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/admin/keys", nil))
+	fmt.Println(w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	(&signedstrings.Configuration{Keys: [][]byte{exampleKey}}).KeyStatusHandler(nil, newKey).ServeHTTP(w, httptest.NewRequest("POST", "/admin/keys", nil))
+	fmt.Println(w.Code, w.Header().Get("Allow"))
+
+	// Output: 200 {"keys":[{"kid":"a814acf20ffba3c8","signing":true,"validations":1}]}
+	// 200 {"keys":[{"kid":"2dd6cc5a150280a3","signing":true,"added_at":1700000000,"validations":0},{"kid":"a814acf20ffba3c8","signing":false,"validations":1}]}
+	// 200 {"keys":[{"kid":"2dd6cc5a150280a3","signing":true,"added_at":1700000000,"age_seconds":3600,"validations":0},{"kid":"a814acf20ffba3c8","signing":false,"validations":1}]}
+	// 405 GET, HEAD
+}
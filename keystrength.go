@@ -0,0 +1,61 @@
+package signedstrings
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseKeysStrict is like ParseKeys, but also rejects keys that are
+// obviously not random, see CheckKeyEntropy. A weak key silently destroys
+// all guarantees, so prefer this for keys typed in by people.
+func ParseKeysStrict(s string) (Keys, error) {
+	keys, err := ParseKeys(s)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if err := CheckKeyEntropy(key); err != nil {
+			return nil, fmt.Errorf("key %d: %w", i+1, err)
+		}
+	}
+	return keys, nil
+}
+
+// CheckKeyEntropy returns an error if the key is obviously low-entropy:
+// mostly repeated bytes, a sequence like 00 01 02..., or hex-encoded text
+// such as a password. Random keys pass with overwhelming probability.
+// Passing does not prove that a key is random.
+func CheckKeyEntropy(key []byte) error {
+	const hint = "; generate a random key with: openssl rand -hex 64"
+	if len(key) == 0 {
+		return errors.New("weak key: empty" + hint)
+	}
+
+	var seen [256]bool
+	var distinct int
+	for _, b := range key {
+		if !seen[b] {
+			seen[b] = true
+			distinct++
+		}
+	}
+	// Long random keys run out of byte values, so at most 128 are required.
+	if distinct < len(key)/2 && distinct < 128 {
+		return fmt.Errorf("weak key: repeated pattern, only %d distinct bytes out of %d"+hint, distinct, len(key))
+	}
+
+	var steady int
+	for i := 2; i < len(key); i++ {
+		if key[i]-key[i-1] == key[i-1]-key[i-2] {
+			steady++
+		}
+	}
+	if 2*steady >= len(key) {
+		return errors.New("weak key: sequential pattern" + hint)
+	}
+
+	if isPrintableASCII(string(key)) {
+		return errors.New("weak key: hex-encoded printable text rather than random bytes" + hint)
+	}
+	return nil
+}
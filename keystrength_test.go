@@ -0,0 +1,44 @@
+package signedstrings_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleParseKeysStrict() {
+	good := hex.EncodeToString(exampleKey)
+	print(signedstrings.ParseKeysStrict(good + " " + strings.Repeat("ab", 32)))
+	print(signedstrings.ParseKeysStrict(strings.Repeat("deadbeef", 8)))
+	print(signedstrings.ParseKeysStrict("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"))
+	print(signedstrings.ParseKeysStrict(hex.EncodeToString([]byte("correct horse battery staple 123"))))
+	keys, err := signedstrings.ParseKeysStrict(good)
+	fmt.Println(len(keys), err)
+
+	// Output: err: key 2: weak key: repeated pattern, only 1 distinct bytes out of 32; generate a random key with: openssl rand -hex 64
+	// err: key 1: weak key: repeated pattern, only 4 distinct bytes out of 32; generate a random key with: openssl rand -hex 64
+	// err: key 1: weak key: sequential pattern; generate a random key with: openssl rand -hex 64
+	// err: key 1: weak key: hex-encoded printable text rather than random bytes; generate a random key with: openssl rand -hex 64
+	// 1 <nil>
+}
+
+func TestCheckKeyEntropy_long(t *testing.T) {
+	key := make([]byte, 1024)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := signedstrings.CheckKeyEntropy(key); err != nil {
+		t.Errorf("random 1 KiB key: %v", err)
+	}
+
+	for i := range key {
+		key[i] = byte(i*7%100 + 1)
+	}
+	if err := signedstrings.CheckKeyEntropy(key); err == nil {
+		t.Errorf("1 KiB key of 100 distinct bytes accepted")
+	}
+}
@@ -0,0 +1,101 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// keyWordsChecksumLen is the number of checksum words EncodeKeyWords appends.
+const keyWordsChecksumLen = 2
+
+// EncodeKeyWords renders a key as space-separated words, one per byte,
+// followed by a checksum, so that it can be read over the phone or written
+// on paper for break-glass recovery. Every word is determined by its first
+// four letters. Use DecodeKeyWords to get the key back.
+func EncodeKeyWords(key []byte) string {
+	sum := sha256.Sum256(key)
+	words := make([]string, 0, len(key)+keyWordsChecksumLen)
+	for _, b := range append(append([]byte(nil), key...), sum[:keyWordsChecksumLen]...) {
+		words = append(words, keyWords[b])
+	}
+	return strings.Join(words, " ")
+}
+
+// DecodeKeyWords parses a key encoded by EncodeKeyWords. Words may be
+// separated by whitespace or commas, are case-insensitive, and may be
+// abbreviated to their first four letters. A mistyped or missing word
+// fails the checksum.
+func DecodeKeyWords(s string) ([]byte, error) {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	if len(fields) <= keyWordsChecksumLen {
+		return nil, fmt.Errorf("signedstrings: need more than %d key words, got %d", keyWordsChecksumLen, len(fields))
+	}
+	raw := make([]byte, len(fields))
+	for i, w := range fields {
+		b, ok := lookupKeyWord(w)
+		if !ok {
+			return nil, fmt.Errorf("signedstrings: unknown key word %q at position %d", w, i+1)
+		}
+		raw[i] = b
+	}
+	key, checksum := raw[:len(raw)-keyWordsChecksumLen], raw[len(raw)-keyWordsChecksumLen:]
+	sum := sha256.Sum256(key)
+	if subtle.ConstantTimeCompare(sum[:keyWordsChecksumLen], checksum) != 1 {
+		return nil, errors.New("signedstrings: key words checksum mismatch")
+	}
+	return key, nil
+}
+
+func lookupKeyWord(w string) (byte, bool) {
+	if len(w) < 4 {
+		return 0, false
+	}
+	for i, kw := range keyWords {
+		if kw == w || len(w) == 4 && strings.HasPrefix(kw, w) {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
+
+// keyWords maps byte values to words for EncodeKeyWords. The words are
+// sorted, and no two share their first four letters. Never reorder.
+var keyWords = [256]string{
+	"acid", "acorn", "actor", "adobe", "agent", "album", "alley", "amber",
+	"anchor", "apple", "apron", "arena", "armor", "arrow", "atlas", "attic",
+	"august", "autumn", "bacon", "badge", "bagel", "baker", "bamboo", "barrel",
+	"basil", "basket", "beacon", "bench", "berry", "bicycle", "bison", "blanket",
+	"bonus", "border", "bottle", "bracket", "bridge", "bronze", "bubble", "bucket",
+	"buffalo", "butter", "cabin", "cactus", "camel", "candle", "canoe", "canyon",
+	"carbon", "carpet", "cattle", "cedar", "cement", "cherry", "cider", "circus",
+	"citrus", "clover", "cobalt", "comet", "copper", "coral", "cotton", "crater",
+	"crayon", "cricket", "crystal", "cupboard", "daisy", "dancer", "delta", "desert",
+	"dinner", "dolphin", "domino", "donkey", "dragon", "dynamo", "eagle", "easel",
+	"echo", "elbow", "ember", "emerald", "engine", "escape", "fabric", "falcon",
+	"feather", "ferry", "fiesta", "finger", "flannel", "fluid", "forest", "fossil",
+	"fountain", "fridge", "frozen", "galaxy", "garden", "garlic", "gazelle", "geyser",
+	"giraffe", "glacier", "goblet", "gopher", "granite", "gravel", "guitar", "gully",
+	"hammer", "harvest", "hazel", "helmet", "hermit", "honey", "hornet", "hotel",
+	"hunter", "husky", "impulse", "indigo", "island", "ivory", "jaguar", "jasmine",
+	"jersey", "jigsaw", "jockey", "jungle", "kayak", "kennel", "kernel", "kidney",
+	"kingdom", "kitten", "koala", "ladder", "lantern", "laptop", "lava", "lemon",
+	"lettuce", "lilac", "limbo", "linen", "lizard", "locket", "lumber", "magnet",
+	"mammoth", "maple", "marble", "meadow", "melon", "mercury", "mirror", "mitten",
+	"monkey", "mosaic", "museum", "mustard", "napkin", "nectar", "needle", "noodle",
+	"nutmeg", "oasis", "ocean", "onion", "opera", "orbit", "orchid", "otter",
+	"paddle", "palace", "panther", "papaya", "parrot", "peanut", "pebble", "pelican",
+	"pepper", "pickle", "pigeon", "pillow", "pirate", "plasma", "pocket", "polka",
+	"potato", "prism", "puzzle", "quartz", "quiver", "rabbit", "radish", "raisin",
+	"raven", "recipe", "ribbon", "rocket", "rodeo", "rooster", "ruby", "saddle",
+	"salmon", "sandal", "satin", "scarf", "sheriff", "shovel", "signal", "silver",
+	"sketch", "sledge", "socket", "sonnet", "spider", "squirrel", "statue", "summit",
+	"sunset", "tango", "teapot", "temple", "thunder", "ticket", "timber", "toaster",
+	"tomato", "topaz", "trumpet", "tulip", "tunnel", "turtle", "tuxedo", "unicorn",
+	"uranium", "valley", "vanilla", "venus", "violin", "visor", "vulture", "wagon",
+	"walrus", "whisker", "willow", "window", "yacht", "yogurt", "zebra", "zephyr",
+}
@@ -0,0 +1,31 @@
+package signedstrings_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleEncodeKeyWords() {
+	words := signedstrings.EncodeKeyWords(exampleKey)
+	fmt.Println(len(strings.Fields(words)))
+
+	key, err := signedstrings.DecodeKeyWords(strings.ToUpper(words))
+	fmt.Println(bytes.Equal(key, exampleKey), err)
+
+	fmt.Println(signedstrings.EncodeKeyWords([]byte{0, 1, 255}))
+	fmt.Println(signedstrings.DecodeKeyWords("acid, ACOR zeph bubb need"))
+	print(signedstrings.DecodeKeyWords("acid actor zephyr bubble needle"))
+	print(signedstrings.DecodeKeyWords("acid axe zephyr bubble needle"))
+	print(signedstrings.DecodeKeyWords("acid acorn"))
+
+	// Output: 34
+	// true <nil>
+	// acid acorn zephyr bubble needle
+	// [0 1 255] <nil>
+	// err: signedstrings: key words checksum mismatch
+	// err: signedstrings: unknown key word "axe" at position 2
+	// err: signedstrings: need more than 2 key words, got 2
+}
@@ -0,0 +1,29 @@
+package signedstrings
+
+import (
+	"crypto/sha1"
+	"fmt"
+)
+
+// NeedsReissue is the error returned for correctly signed strings in
+// a legacy format that must be replaced with Transcode, see
+// Configuration.LegacySHA1Until. It wraps InvalidSig.
+var NeedsReissue = fmt.Errorf("%w: legacy signature, needs reissue", InvalidSig)
+
+// legacySHA1 verifies strings of older signers. It is not registered, so it
+// can neither sign nor be listed in Algorithms.
+var legacySHA1 = &Algorithm{Name: "HS1", Hash: sha1.New}
+
+// isLegacySHA1 returns whether tok looks like an HMAC-SHA1 string that
+// should be verified as such, see LegacySHA1Until.
+func (conf *Configuration) isLegacySHA1(tok token) bool {
+	if conf.LegacySHA1Until.IsZero() || tok.alg != DefaultAlgorithm || tok.version != 1 || conf.now().After(conf.LegacySHA1Until) {
+		return false
+	}
+	for _, enc := range conf.macEncodings() {
+		if len(tok.mac) == enc.encodedLen(sha1.Size) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package signedstrings_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_LegacySHA1Until() {
+	// a string produced by an older signer
+	h := hmac.New(sha1.New, exampleKey)
+	h.Write([]byte("foo"))
+	legacy := "foo-" + hex.EncodeToString(h.Sum(nil))
+
+	now := time.Unix(1700000000, 0)
+	conf := signedstrings.Configuration{
+		Keys:            [][]byte{exampleKey},
+		LegacySHA1Until: now.Add(30 * 24 * time.Hour),
+		Now:             func() time.Time { return now },
+	}
+	_, err := conf.Validate(legacy)
+	fmt.Println(err, errors.Is(err, signedstrings.InvalidSig))
+	fmt.Println(conf.Transcode(legacy))
+	print(conf.Transcode("foo-" + hex.EncodeToString(make([]byte, sha1.Size))))
+
+	now = now.Add(31 * 24 * time.Hour)
+	print(conf.Transcode(legacy))
+
+	// Output: invalid signature: legacy signature, needs reissue true
+	// foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334 <nil>
+	// err: invalid signature
+	// err: invalid signature: malformed
+}
@@ -0,0 +1,26 @@
+package signedstrings
+
+import "crypto/subtle"
+
+// macLabel derives the keys of ComputeMAC from the configured keys.
+const macLabel = "signedstrings mac"
+
+// ComputeMAC returns an HMAC-SHA256 of data keyed with a key derived from
+// the first configured key, for callers building custom protocols that
+// only need the keyed MAC. The derived key keeps these MACs from passing
+// as signatures of signed strings, and vice versa. Use VerifyMAC to check.
+// Panics if an external key fails.
+func (conf *Configuration) ComputeMAC(data []byte) []byte {
+	return conf.keyedMACs(macLabel, data, 1)[0]
+}
+
+// VerifyMAC returns whether mac is the ComputeMAC of data under any of the
+// configured keys, so MACs computed before a key rotation still verify.
+// Comparison is constant-time.
+func (conf *Configuration) VerifyMAC(data, mac []byte) bool {
+	var match int
+	for _, expected := range conf.keyedMACs(macLabel, data, -1) {
+		match |= subtle.ConstantTimeCompare(expected, mac)
+	}
+	return match == 1
+}
@@ -0,0 +1,26 @@
+package signedstrings_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_ComputeMAC() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	mac := conf.ComputeMAC([]byte("foo"))
+	fmt.Printf("%x\n", mac)
+	fmt.Println(conf.VerifyMAC([]byte("foo"), mac), conf.VerifyMAC([]byte("bar"), mac))
+
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	conf.Keys = [][]byte{newKey, exampleKey}
+	fmt.Println(conf.VerifyMAC([]byte("foo"), mac), bytes.Equal(conf.ComputeMAC([]byte("foo")), mac))
+
+	// Output: c150ad524fd6b65d658e67dcdfec22c115c21f224515f1c7dbe240f7a1f3f215
+	// true false
+	// true false
+}
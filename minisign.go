@@ -0,0 +1,107 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// minisignAlg identifies legacy (non-prehashed) Ed25519 signatures, which
+// both minisign and OpenBSD signify verify.
+const minisignAlg = "Ed"
+
+// Minisign returns a minisign-compatible detached signature of message,
+// made with the signing key of an Ed25519 configuration. The result is meant
+// to be saved as <file>.minisig and checked with `minisign -V`.
+//
+// The trusted comment is signed along with the message; minisign displays
+// it after a successful verification.
+func (conf *Configuration) Minisign(message []byte, trustedComment string) (string, error) {
+	keyNum, sig, err := conf.signifySign(message)
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(trustedComment, "\r\n") {
+		return "", errors.New("signedstrings: trusted comment must be a single line")
+	}
+	global, err := conf.keys()[0].MAC(Ed25519, append(sig[:len(sig):len(sig)], trustedComment...))
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	buf.WriteString("untrusted comment: signature from signedstrings secret key\n")
+	buf.WriteString(encodeSignifyBlob(keyNum, sig))
+	buf.WriteString("\ntrusted comment: ")
+	buf.WriteString(trustedComment)
+	buf.WriteString("\n")
+	buf.WriteString(base64.StdEncoding.EncodeToString(global))
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// Signify returns an OpenBSD signify-compatible detached signature of message,
+// made with the signing key of an Ed25519 configuration. The result is meant
+// to be saved as <file>.sig and checked with `signify -V`.
+func (conf *Configuration) Signify(message []byte) (string, error) {
+	keyNum, sig, err := conf.signifySign(message)
+	if err != nil {
+		return "", err
+	}
+	return "untrusted comment: signature from signedstrings secret key\n" + encodeSignifyBlob(keyNum, sig) + "\n", nil
+}
+
+// MinisignPublicKey returns the public key of an Ed25519 configuration
+// in the format shared by minisign and signify.
+func (conf *Configuration) MinisignPublicKey() (string, error) {
+	pub, err := conf.signifyPublicKey()
+	if err != nil {
+		return "", err
+	}
+	keyNum := signifyKeyNum(pub)
+	return "untrusted comment: signedstrings public key\n" + encodeSignifyBlob(keyNum, pub) + "\n", nil
+}
+
+func (conf *Configuration) signifySign(message []byte) (keyNum, sig []byte, err error) {
+	pub, err := conf.signifyPublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	if conf.IsVerifyOnly() {
+		return nil, nil, errors.New("signedstrings: cannot sign with a verify-only configuration")
+	}
+	sig, err = conf.keys()[0].MAC(Ed25519, message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signifyKeyNum(pub), sig, nil
+}
+
+func (conf *Configuration) signifyPublicKey() ([]byte, error) {
+	conf.sanityCheck()
+	if conf.algorithms()[0] != Ed25519 {
+		return nil, errors.New("signedstrings: minisign and signify require Ed25519")
+	}
+	if keys := conf.ActiveKeys(); len(keys) > 0 {
+		return Ed25519.Public(keys[0])
+	} else if len(conf.PublicKeys) > 0 {
+		return conf.PublicKeys[0], nil
+	}
+	return nil, errors.New("signedstrings: minisign and signify need a public key for external keys")
+}
+
+// signifyKeyNum derives a stable 8-byte key number from the public key;
+// the tools only use it to match signatures with keys.
+func signifyKeyNum(pub []byte) []byte {
+	h := sha256.Sum256(pub)
+	return h[:8]
+}
+
+func encodeSignifyBlob(keyNum, data []byte) string {
+	blob := make([]byte, 0, len(minisignAlg)+len(keyNum)+len(data))
+	blob = append(blob, minisignAlg...)
+	blob = append(blob, keyNum...)
+	blob = append(blob, data...)
+	return base64.StdEncoding.EncodeToString(blob)
+}
@@ -0,0 +1,31 @@
+package signedstrings_test
+
+import (
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_Minisign() {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"Ed25519"},
+	}
+
+	fmt.Print(must(conf.MinisignPublicKey()))
+	fmt.Print(must(conf.Minisign([]byte("hello\n"), "file:hello.txt")))
+	fmt.Print(must(conf.Signify([]byte("hello\n"))))
+
+	hmac := signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	print(hmac.Minisign([]byte("hello\n"), ""))
+
+	// Output: untrusted comment: signedstrings public key
+	// RWTi8UNmiTnNPmAup56yn5/u65ABxRjxBmQsnILQUvFCky6OeRUTVZ4W
+	// untrusted comment: signature from signedstrings secret key
+	// RWTi8UNmiTnNPpLqErZTmI3kD+FITLi/jD4Mgzy6/UAJhB36P3dkmbx0AgwEgv77UhR61veUNqq/JfK1Y11uqvJlRdsMUs1IrwE=
+	// trusted comment: file:hello.txt
+	// Ru3vhXxwjuZG9WupZsQYuMByEpPj2fkAA30XkwwoucEEiT0rxkm9XeKcKwhCOukxqs9VR9GQUM51qQpzqHkODg==
+	// untrusted comment: signature from signedstrings secret key
+	// RWTi8UNmiTnNPpLqErZTmI3kD+FITLi/jD4Mgzy6/UAJhB36P3dkmbx0AgwEgv77UhR61veUNqq/JfK1Y11uqvJlRdsMUs1IrwE=
+	// err: signedstrings: minisign and signify require Ed25519
+}
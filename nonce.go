@@ -0,0 +1,59 @@
+package signedstrings
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces (one-time identifiers) to detect replays.
+type NonceStore interface {
+	// Use records the nonce and returns true, or returns false if the nonce
+	// has already been used. The nonce may be forgotten after expires.
+	Use(nonce string, expires time.Time) (bool, error)
+}
+
+// ContextNonceStore is a NonceStore that accepts a context, so that calls
+// to a shared store honor timeouts and cancellation. Context-aware methods
+// like Envelopes.OpenContext call UseContext instead of Use.
+type ContextNonceStore interface {
+	NonceStore
+	UseContext(ctx context.Context, nonce string, expires time.Time) (bool, error)
+}
+
+func useNonce(ctx context.Context, store NonceStore, nonce string, expires time.Time) (bool, error) {
+	if s, ok := store.(ContextNonceStore); ok {
+		return s.UseContext(ctx, nonce, expires)
+	}
+	return store.Use(nonce, expires)
+}
+
+// MemoryNonceStore is an in-process NonceStore. The zero value is ready
+// to use. Use a shared store when running several instances.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	now    time.Time
+}
+
+func (s *MemoryNonceStore) Use(nonce string, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nonces == nil {
+		s.nonces = make(map[string]time.Time)
+	}
+	now := time.Now()
+	if now.Sub(s.now) > time.Minute {
+		for n, exp := range s.nonces {
+			if now.After(exp) {
+				delete(s.nonces, n)
+			}
+		}
+		s.now = now
+	}
+	if exp, found := s.nonces[nonce]; found && !now.After(exp) {
+		return false, nil
+	}
+	s.nonces[nonce] = expires
+	return true, nil
+}
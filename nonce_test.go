@@ -0,0 +1,25 @@
+package signedstrings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	var store signedstrings.MemoryNonceStore
+	future := time.Now().Add(time.Hour)
+	if ok, _ := store.Use("a", future); !ok {
+		t.Error("first use rejected")
+	}
+	if ok, _ := store.Use("a", future); ok {
+		t.Error("second use accepted")
+	}
+	if ok, _ := store.Use("b", time.Now().Add(-time.Second)); !ok {
+		t.Error("first use of b rejected")
+	}
+	if ok, _ := store.Use("b", future); !ok {
+		t.Error("use of expired b rejected")
+	}
+}
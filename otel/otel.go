@@ -0,0 +1,145 @@
+// Package otel instruments signedstrings with OpenTelemetry: Sign and
+// Validate calls produce spans and metrics, so token operations show up in
+// existing traces and dashboards. Data and signatures are never recorded.
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	global "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "github.com/andreyvit/signedstrings/otel"
+
+// Attribute keys set on spans and metrics.
+const (
+	OutcomeKey  = attribute.Key("signedstrings.outcome")
+	PrefixKey   = attribute.Key("signedstrings.prefix")
+	KeyIDKey    = attribute.Key("signedstrings.key_id")
+	KeyIndexKey = attribute.Key("signedstrings.key_index")
+	AgeKey      = attribute.Key("signedstrings.token_age")
+)
+
+// Configuration wraps a signedstrings configuration with instrumented
+// methods. Create it with New.
+type Configuration struct {
+	Conf *signedstrings.Configuration
+
+	tracer      trace.Tracer
+	signs       metric.Int64Counter
+	validations metric.Int64Counter
+	ages        metric.Float64Histogram
+}
+
+// New instruments conf using the given providers; nil means the global
+// ones set with go.opentelemetry.io/otel.
+func New(conf *signedstrings.Configuration, tp trace.TracerProvider, mp metric.MeterProvider) (*Configuration, error) {
+	if tp == nil {
+		tp = global.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = global.GetMeterProvider()
+	}
+	meter := mp.Meter(scope)
+	c := &Configuration{Conf: conf, tracer: tp.Tracer(scope)}
+	var err error
+	c.signs, err = meter.Int64Counter("signedstrings.signs",
+		metric.WithDescription("Number of strings signed"),
+		metric.WithUnit("{string}"))
+	if err != nil {
+		return nil, err
+	}
+	c.validations, err = meter.Int64Counter("signedstrings.validations",
+		metric.WithDescription("Number of strings validated, by outcome"),
+		metric.WithUnit("{string}"))
+	if err != nil {
+		return nil, err
+	}
+	c.ages, err = meter.Float64Histogram("signedstrings.token.age",
+		metric.WithDescription("Age of validated strings carrying a timestamp"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Sign is like signedstrings.Configuration.SignContext, recorded as
+// a signedstrings.sign span.
+func (c *Configuration) Sign(ctx context.Context, data string) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "signedstrings.sign", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	signed, err := c.Conf.SignContext(ctx, data)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	attrs := []attribute.KeyValue{OutcomeKey.String(outcome)}
+	span.SetAttributes(attrs...)
+	c.signs.Add(ctx, 1, metric.WithAttributes(attrs...))
+	return signed, err
+}
+
+// Validate is like signedstrings.Configuration.ValidateDetailedContext,
+// recorded as a signedstrings.validate span carrying the outcome, the
+// matched prefix, the key and the age of the string.
+func (c *Configuration) Validate(ctx context.Context, signed string) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "signedstrings.validate", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	v, err := c.Conf.ValidateDetailedContext(ctx, signed)
+	outcome := Outcome(err)
+	attrs := []attribute.KeyValue{OutcomeKey.String(outcome)}
+	if err == nil {
+		attrs = append(attrs, PrefixKey.String(v.Pattern), KeyIDKey.String(v.KeyID))
+		if i := c.keyIndex(v.KeyID); i >= 0 {
+			attrs = append(attrs, KeyIndexKey.Int(i))
+		}
+	}
+	c.validations.Add(ctx, 1, metric.WithAttributes(attrs...))
+
+	if err == nil { // timestamps of strings that failed validation may be forged
+		if insp, ierr := c.Conf.Inspect(signed); ierr == nil && !insp.IssuedAt.IsZero() {
+			age := time.Since(insp.IssuedAt).Seconds()
+			if c.Conf.Now != nil {
+				age = c.Conf.Now().Sub(insp.IssuedAt).Seconds()
+			}
+			c.ages.Record(ctx, age, metric.WithAttributes(OutcomeKey.String(outcome)))
+			attrs = append(attrs, AgeKey.Float64(age))
+		}
+	}
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.SetStatus(codes.Error, outcome)
+		if outcome == "error" {
+			span.RecordError(err)
+		}
+	}
+	return v.Data, err
+}
+
+// keyIndex returns the index of the key with the given ID among the keys
+// of the configuration, or -1.
+func (c *Configuration) keyIndex(keyID string) int {
+	for i, key := range c.Conf.ActiveKeys() {
+		if signedstrings.KeyID(key) == keyID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Outcome classifies the result of a validation for the outcome attribute,
+// see signedstrings.Reason.
+func Outcome(err error) string {
+	return signedstrings.Reason(err)
+}
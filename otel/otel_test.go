@@ -0,0 +1,128 @@
+package otel_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	signedotel "github.com/andreyvit/signedstrings/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestValidate(t *testing.T) {
+	key, _ := hex.DecodeString("d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2")
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys:      signedstrings.Keys{key},
+		Prefixes:  []string{"tok_"},
+		Timestamp: true,
+		Now:       func() time.Time { return now },
+	}
+	sr := tracetest.NewSpanRecorder()
+	c, err := signedotel.New(conf, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)), noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := c.Sign(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if data, err := c.Validate(context.Background(), signed); err != nil || data != "foo" {
+		t.Fatalf("Validate = %q, %v", data, err)
+	}
+	if _, err := c.Validate(context.Background(), signed+"0"); err == nil {
+		t.Fatal("Validate accepted a tampered string")
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	attrs := attribute.NewSet(spans[1].Attributes()...)
+	for k, want := range map[attribute.Key]string{
+		signedotel.OutcomeKey: "valid",
+		signedotel.PrefixKey:  "tok_",
+		signedotel.KeyIDKey:   signedstrings.KeyID(key),
+	} {
+		if v, _ := attrs.Value(k); v.AsString() != want {
+			t.Errorf("%s = %q, wanted %q", k, v.AsString(), want)
+		}
+	}
+	if v, _ := attrs.Value(signedotel.AgeKey); v.AsFloat64() != 60 {
+		t.Errorf("%s = %v, wanted 60", signedotel.AgeKey, v.AsFloat64())
+	}
+	tampered := attribute.NewSet(spans[2].Attributes()...)
+	if v, _ := tampered.Value(signedotel.OutcomeKey); v.AsString() != "malformed_signature" {
+		t.Errorf("tampered outcome = %q", v.AsString())
+	}
+	if tampered.HasValue(signedotel.AgeKey) {
+		t.Errorf("age recorded for a tampered string")
+	}
+}
+
+type ctxKey struct{}
+
+// ctxHSM checks that the context reaches external keys.
+type ctxHSM struct {
+	key []byte
+	t   *testing.T
+}
+
+func (k ctxHSM) MAC(alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	k.t.Error("MAC called instead of MACContext")
+	return nil, errors.New("no context")
+}
+
+func (k ctxHSM) MACContext(ctx context.Context, alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	if ctx.Value(ctxKey{}) == nil {
+		k.t.Error("context not passed")
+	}
+	h := hmac.New(alg.Hash, k.key)
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+func TestValidate_context(t *testing.T) {
+	key, _ := hex.DecodeString("d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2")
+	conf := &signedstrings.Configuration{ExternalKeys: []signedstrings.ExternalKey{ctxHSM{key, t}}}
+	c, err := signedotel.New(conf, nil, noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+	signed, err := c.Sign(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := c.Validate(ctx, signed); err != nil || data != "foo" {
+		t.Errorf("Validate = %q, %v", data, err)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, "valid"},
+		{signedstrings.Expired, "expired"},
+		{signedstrings.RetiredKey, "retired_key"},
+		{signedstrings.InvalidSig, "invalid_signature"},
+		{signedstrings.Invalid, "invalid"},
+		{errors.New("HSM unavailable"), "error"},
+	}
+	for _, tt := range tests {
+		if got := signedotel.Outcome(tt.err); got != tt.want {
+			t.Errorf("Outcome(%v) = %q, wanted %q", tt.err, got, tt.want)
+		}
+	}
+}
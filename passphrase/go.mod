@@ -0,0 +1,12 @@
+module github.com/andreyvit/signedstrings/passphrase
+
+go 1.20
+
+require (
+	github.com/andreyvit/signedstrings v0.0.0-00010101000000-000000000000
+	golang.org/x/crypto v0.21.0
+)
+
+require golang.org/x/sys v0.18.0 // indirect
+
+replace github.com/andreyvit/signedstrings => ../
@@ -0,0 +1,153 @@
+// Package passphrase derives signedstrings keys from human-memorable
+// passphrases, for small tools and CLIs where managing random keys is
+// impractical. Prefer random keys wherever possible: a derived key is only
+// as strong as the passphrase.
+//
+// A setting string records the algorithm, its cost parameters and the salt
+// in the PHC format, e.g. $argon2id$v=19$m=65536,t=1,p=4$c2FsdHNhbHRzYWx0c2FsdA,
+// so that keys can be re-derived later even after the defaults change.
+// Settings are not secret; store them next to the configuration.
+//
+// Argon2id is the default. Scrypt is offered for environments whose crypto
+// policy disallows Argon2, with settings in the same format, e.g.
+// $scrypt$ln=15,r=8,p=1$c2FsdHNhbHRzYWx0c2FsdA.
+package passphrase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyLen is the length of derived keys, in bytes.
+const KeyLen = 64
+
+// MinSaltLen is the minimum accepted salt length, in bytes.
+const MinSaltLen = 16
+
+// Params are the Argon2id cost parameters.
+type Params struct {
+	Time    uint32 // number of passes
+	Memory  uint32 // in KiB
+	Threads uint8
+}
+
+// DefaultParams follow the second recommended option of RFC 9106.
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// ScryptParams are the scrypt cost parameters.
+type ScryptParams struct {
+	LogN uint8 // log2 of the CPU/memory cost N
+	R    int   // block size
+	P    int   // parallelization
+}
+
+// DefaultScryptParams are the interactive login parameters recommended
+// by the scrypt paper, raised to N=2^15.
+var DefaultScryptParams = ScryptParams{LogN: 15, R: 8, P: 1}
+
+// DeriveKey derives a KeyLen-byte key from the passphrase using Argon2id.
+// The same passphrase, salt and params always yield the same key.
+func DeriveKey(passphrase string, salt []byte, params Params) ([]byte, error) {
+	if err := checkInputs(passphrase, salt); err != nil {
+		return nil, err
+	} else if params.Time == 0 || params.Threads == 0 || params.Memory < 8*uint32(params.Threads) {
+		return nil, errors.New("passphrase: invalid Argon2id parameters")
+	}
+	return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, KeyLen), nil
+}
+
+// DeriveKeyScrypt is like DeriveKey, but uses scrypt.
+func DeriveKeyScrypt(passphrase string, salt []byte, params ScryptParams) ([]byte, error) {
+	if err := checkInputs(passphrase, salt); err != nil {
+		return nil, err
+	} else if params.LogN == 0 || params.LogN > 30 || params.R <= 0 || params.P <= 0 {
+		return nil, errors.New("passphrase: invalid scrypt parameters")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<params.LogN, params.R, params.P, KeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("passphrase: %w", err)
+	}
+	return key, nil
+}
+
+func checkInputs(passphrase string, salt []byte) error {
+	if passphrase == "" {
+		return errors.New("passphrase: empty passphrase")
+	} else if len(salt) < MinSaltLen {
+		return fmt.Errorf("passphrase: %d-byte salt is too short, need at least %d bytes", len(salt), MinSaltLen)
+	}
+	return nil
+}
+
+// NewSetting returns a setting string with the given params and a new
+// random salt, see DeriveKeyFromSetting.
+func NewSetting(params Params) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s", argon2.Version, params.Memory, params.Time, params.Threads, salt), nil
+}
+
+// NewScryptSetting is like NewSetting, but for scrypt.
+func NewScryptSetting(params ScryptParams) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s", params.LogN, params.R, params.P, salt), nil
+}
+
+func newSalt() (string, error) {
+	salt := make([]byte, MinSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(salt), nil
+}
+
+// DeriveKeyFromSetting derives a key from the passphrase using the
+// algorithm, params and salt recorded in a setting string.
+func DeriveKeyFromSetting(passphrase, setting string) ([]byte, error) {
+	parts := strings.Split(setting, "$")
+	if len(parts) < 2 || parts[0] != "" {
+		return nil, errors.New("passphrase: invalid setting")
+	}
+	switch parts[1] {
+	case "argon2id":
+		var version int
+		var params Params
+		if len(parts) != 5 {
+			return nil, errors.New("passphrase: invalid setting")
+		} else if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return nil, fmt.Errorf("passphrase: unsupported Argon2 version %q", parts[2])
+		} else if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+			return nil, fmt.Errorf("passphrase: invalid Argon2id parameters %q", parts[3])
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return nil, errors.New("passphrase: invalid salt")
+		}
+		return DeriveKey(passphrase, salt, params)
+	case "scrypt":
+		var params ScryptParams
+		if len(parts) != 4 {
+			return nil, errors.New("passphrase: invalid setting")
+		} else if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &params.LogN, &params.R, &params.P); err != nil {
+			return nil, fmt.Errorf("passphrase: invalid scrypt parameters %q", parts[2])
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+		if err != nil {
+			return nil, errors.New("passphrase: invalid salt")
+		}
+		return DeriveKeyScrypt(passphrase, salt, params)
+	default:
+		return nil, fmt.Errorf("passphrase: unsupported algorithm %q", parts[1])
+	}
+}
@@ -0,0 +1,112 @@
+package passphrase_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/passphrase"
+)
+
+var (
+	cheap       = passphrase.Params{Time: 1, Memory: 64, Threads: 1}
+	cheapScrypt = passphrase.ScryptParams{LogN: 4, R: 8, P: 1}
+)
+
+func TestDeriveKeyFromSetting(t *testing.T) {
+	setting, err := passphrase.NewSetting(cheap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(setting, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("setting = %q", setting)
+	}
+	a, err := passphrase.DeriveKeyFromSetting("correct horse", setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := passphrase.DeriveKeyFromSetting("correct horse", setting)
+	c, _ := passphrase.DeriveKeyFromSetting("correct horsf", setting)
+	if len(a) != passphrase.KeyLen || !bytes.Equal(a, b) || bytes.Equal(a, c) {
+		t.Errorf("keys = %x %x %x", a, b, c)
+	}
+
+	conf := signedstrings.Configuration{Keys: [][]byte{a}}
+	if err := conf.Check(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeriveKeyFromSetting_scrypt(t *testing.T) {
+	setting, err := passphrase.NewScryptSetting(cheapScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(setting, "$scrypt$ln=4,r=8,p=1$") {
+		t.Errorf("setting = %q", setting)
+	}
+	a, err := passphrase.DeriveKeyFromSetting("correct horse", setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := passphrase.DeriveKeyScrypt("correct horse", decodeSalt(t, setting), cheapScrypt)
+	c, _ := passphrase.DeriveKeyFromSetting("correct horsf", setting)
+	if len(a) != passphrase.KeyLen || !bytes.Equal(a, b) || bytes.Equal(a, c) {
+		t.Errorf("keys = %x %x %x", a, b, c)
+	}
+}
+
+func decodeSalt(t *testing.T, setting string) []byte {
+	salt, err := base64.RawStdEncoding.DecodeString(setting[strings.LastIndex(setting, "$")+1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return salt
+}
+
+func TestDeriveKey_invalid(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	tests := []struct {
+		pass   string
+		salt   []byte
+		params passphrase.Params
+		err    string
+	}{
+		{"", salt, cheap, "passphrase: empty passphrase"},
+		{"x", salt[:8], cheap, "passphrase: 8-byte salt is too short, need at least 16 bytes"},
+		{"x", salt, passphrase.Params{}, "passphrase: invalid Argon2id parameters"},
+	}
+	for _, tt := range tests {
+		_, err := passphrase.DeriveKey(tt.pass, tt.salt, tt.params)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("DeriveKey(%q, %q, %v) err = %v, wanted %s", tt.pass, tt.salt, tt.params, err, tt.err)
+		}
+	}
+	_, err := passphrase.DeriveKeyScrypt("x", salt, passphrase.ScryptParams{LogN: 4})
+	if err == nil || err.Error() != "passphrase: invalid scrypt parameters" {
+		t.Errorf("DeriveKeyScrypt err = %v", err)
+	}
+}
+
+func TestDeriveKeyFromSetting_invalid(t *testing.T) {
+	tests := []struct {
+		setting string
+		err     string
+	}{
+		{"argon2id", "passphrase: invalid setting"},
+		{"$bcrypt$10$xyz", `passphrase: unsupported algorithm "bcrypt"`},
+		{"$argon2id$v=16$m=64,t=1,p=1$MDEyMzQ1Njc4OWFiY2RlZg", `passphrase: unsupported Argon2 version "v=16"`},
+		{"$argon2id$v=19$t=1$MDEyMzQ1Njc4OWFiY2RlZg", `passphrase: invalid Argon2id parameters "t=1"`},
+		{"$argon2id$v=19$m=64,t=1,p=1$!!", "passphrase: invalid salt"},
+		{"$scrypt$ln=4,r=8,p=1", "passphrase: invalid setting"},
+		{"$scrypt$n=16$MDEyMzQ1Njc4OWFiY2RlZg", `passphrase: invalid scrypt parameters "n=16"`},
+	}
+	for _, tt := range tests {
+		_, err := passphrase.DeriveKeyFromSetting("x", tt.setting)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("DeriveKeyFromSetting(%q) err = %v, wanted %s", tt.setting, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/andreyvit/signedstrings/pkcs11key
+
+go 1.20
+
+require (
+	github.com/andreyvit/signedstrings v0.0.0-00010101000000-000000000000
+	github.com/miekg/pkcs11 v1.1.2
+)
+
+replace github.com/andreyvit/signedstrings => ../
@@ -0,0 +1,122 @@
+// Package pkcs11key lets signedstrings use HMAC keys stored in a PKCS#11
+// token (SoftHSM, Luna, YubiHSM and the like) via Configuration.ExternalKeys.
+package pkcs11key
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/miekg/pkcs11"
+)
+
+// Key is an HMAC key held by a PKCS#11 token. Implements signedstrings.ExternalKey.
+type Key struct {
+	Ctx     *pkcs11.Ctx
+	Session pkcs11.SessionHandle
+	Object  pkcs11.ObjectHandle
+
+	// PKCS#11 sessions cannot run concurrent operations.
+	mu    sync.Mutex
+	owned bool
+}
+
+var mechanisms = map[string]uint{
+	"HS256": pkcs11.CKM_SHA256_HMAC,
+	"HS384": pkcs11.CKM_SHA384_HMAC,
+	"HS512": pkcs11.CKM_SHA512_HMAC,
+}
+
+// Open loads the given PKCS#11 module, logs into the slot with the PIN,
+// and finds the secret key with the given label. Call Close when done.
+func Open(module string, slot uint, pin, label string) (*Key, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("pkcs11key: cannot load %s", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("pkcs11key: initialize: %w", err)
+	}
+	k := &Key{Ctx: ctx, owned: true}
+
+	var err error
+	k.Session, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		k.Close()
+		return nil, fmt.Errorf("pkcs11key: open session: %w", err)
+	}
+	if err := ctx.Login(k.Session, pkcs11.CKU_USER, pin); err != nil {
+		k.Close()
+		return nil, fmt.Errorf("pkcs11key: login: %w", err)
+	}
+	k.Object, err = findKey(ctx, k.Session, label)
+	if err != nil {
+		k.Close()
+		return nil, err
+	}
+	return k, nil
+}
+
+func findKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, label string) (pkcs11.ObjectHandle, error) {
+	err := ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11key: find %q: %w", label, err)
+	}
+	objs, _, err := ctx.FindObjects(session, 2)
+	ctx.FindObjectsFinal(session)
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11key: find %q: %w", label, err)
+	} else if len(objs) == 0 {
+		return 0, fmt.Errorf("pkcs11key: key %q not found", label)
+	} else if len(objs) > 1 {
+		return 0, fmt.Errorf("pkcs11key: multiple keys labeled %q", label)
+	}
+	return objs[0], nil
+}
+
+// MAC asks the token to compute the HMAC of message.
+func (k *Key) MAC(alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	mech, ok := mechanisms[alg.Name]
+	if !ok {
+		return nil, fmt.Errorf("pkcs11key: unsupported algorithm %s", alg.Name)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.Ctx == nil {
+		return nil, errors.New("pkcs11key: key is closed")
+	}
+	err := k.Ctx.SignInit(k.Session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mech, nil)}, k.Object)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11key: %w", err)
+	}
+	mac, err := k.Ctx.Sign(k.Session, message)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11key: %w", err)
+	}
+	return mac, nil
+}
+
+// Close logs out and releases the module if the key was obtained via Open.
+// Keys constructed by hand are left alone, the caller owns their session.
+func (k *Key) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.owned || k.Ctx == nil {
+		return nil
+	}
+	ctx := k.Ctx
+	k.Ctx = nil
+	if k.Session != 0 {
+		ctx.Logout(k.Session)
+		ctx.CloseSession(k.Session)
+	}
+	err := ctx.Finalize()
+	ctx.Destroy()
+	return err
+}
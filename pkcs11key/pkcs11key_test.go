@@ -0,0 +1,26 @@
+package pkcs11key_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/pkcs11key"
+)
+
+var _ signedstrings.ExternalKey = (*pkcs11key.Key)(nil)
+
+func TestMAC_unsupportedAlgorithm(t *testing.T) {
+	var k pkcs11key.Key
+	_, err := k.MAC(&signedstrings.Algorithm{Name: "Custom", Hash: sha256.New}, []byte("foo"))
+	if err == nil || err.Error() != "pkcs11key: unsupported algorithm Custom" {
+		t.Errorf("MAC err = %v", err)
+	}
+}
+
+func TestOpen_missingModule(t *testing.T) {
+	_, err := pkcs11key.Open("/nonexistent/libsofthsm2.so", 0, "1234", "signing")
+	if err == nil {
+		t.Fatal("Open succeeded")
+	}
+}
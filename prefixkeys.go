@@ -0,0 +1,107 @@
+package signedstrings
+
+import (
+	"context"
+	"errors"
+)
+
+// SignWithPrefix is like TrySign, but adds the given prefix, which must be
+// one of Prefixes or match one of the patterns, instead of the first one.
+func (conf *Configuration) SignWithPrefix(prefix, data string) (string, error) {
+	conf.sanityCheck()
+	if _, ok := conf.prefixEntry(prefix); !ok {
+		return "", errors.New("signedstrings: unknown prefix " + prefix)
+	}
+	return conf.signWithPrefix(prefix, data)
+}
+
+// ValidateWithPrefix is like Validate, but only accepts strings with one of
+// the given prefixes, so that a call site can require a specific token class
+// even though the configuration accepts several. Panics if a prefix is not
+// one of Prefixes.
+func (conf *Configuration) ValidateWithPrefix(signed string, allowedPrefixes ...string) (string, error) {
+	for _, prefix := range allowedPrefixes {
+		if !containsString(conf.prefixes(), prefix) {
+			panic("signedstrings: unknown prefix " + prefix)
+		}
+	}
+	v, err := conf.validateToken(context.Background(), signed, nil)
+	if err != nil {
+		return "", err
+	}
+	if !containsString(allowedPrefixes, v.prefix) {
+		return "", Invalid
+	}
+	return conf.transform(signed, v.data)
+}
+
+// forPrefix returns the configuration handling strings with the given
+// prefix: a sub-configuration if the prefix has its own keys, otherwise conf.
+func (conf *Configuration) forPrefix(prefix string) *Configuration {
+	if len(conf.PrefixKeys) == 0 && len(conf.PrefixPolicies) == 0 {
+		return conf
+	}
+	prefix, _ = conf.prefixEntry(prefix)
+	if !conf.hasPrefixConf(prefix) {
+		return conf
+	}
+	if st := conf.compiled(); st != nil {
+		return st.prefixConfs[prefix]
+	}
+	return conf.prefixConf(prefix)
+}
+
+func (conf *Configuration) hasPrefixConf(prefix string) bool {
+	_, keyed := conf.PrefixKeys[prefix]
+	_, ok := conf.PrefixPolicies[prefix]
+	return keyed || ok
+}
+
+// prefixConf builds the sub-configuration for a prefix with its own keys
+// or policy. Public keys are not inherited by prefixes with their own keys,
+// so that they cannot cross-validate.
+func (conf *Configuration) prefixConf(prefix string) *Configuration {
+	policy := conf.PrefixPolicies[prefix]
+	var sub *Configuration
+	if keys, ok := conf.PrefixKeys[prefix]; ok {
+		sub = conf.derive(keys, []string{prefix})
+	} else if len(policy.Keys) > 0 {
+		sub = conf.derive(policy.Keys, []string{prefix})
+	} else {
+		sub = conf.derive(conf.Keys, []string{prefix})
+		sub.ExternalKeys = conf.ExternalKeys
+		sub.PublicKeys = conf.PublicKeys
+	}
+	policy.apply(sub)
+	return sub
+}
+
+func (conf *Configuration) allPrefixesKeyed() bool {
+	if len(conf.PrefixKeys) == 0 && len(conf.PrefixPolicies) == 0 {
+		return false
+	}
+	for _, prefix := range conf.prefixes() {
+		if _, ok := conf.PrefixKeys[prefix]; !ok && len(conf.PrefixPolicies[prefix].Keys) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func Example_prefixKeys() {
+	sessionKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	apiKey := must(hex.DecodeString("283d54389c394ed33ba4146eff7b4133f7e393cb905d089a06798456a1cb7dcd"))
+	conf := &signedstrings.Configuration{
+		Prefixes: []string{"SESS-", "API-"},
+		PrefixKeys: map[string]signedstrings.Keys{
+			"SESS-": {sessionKey},
+			"API-":  {apiKey},
+		},
+	}
+
+	sess := conf.Sign("foo")
+	api := must(conf.SignWithPrefix("API-", "foo"))
+	fmt.Println(sess)
+	fmt.Println(api)
+	print(conf.Validate(sess))
+	print(conf.Validate(api))
+
+	// a session signature does not validate as an API token
+	print(conf.Validate("API-" + sess[len("SESS-"):]))
+	print(conf.SignWithPrefix("OTHER-", "foo"))
+
+	// Output: SESS-foo-e5f36b4348882e28b168b2ee5292f702ed501adacd3dd9a284417041b27d141d
+	// API-foo-12c2c025793cad26c1f1a88bf8601011f4ff828e9a438649630f1f9c8c148106
+	// foo
+	// foo
+	// err: invalid signature
+	// err: signedstrings: unknown prefix OTHER-
+}
+
+func TestPrefixKeys(t *testing.T) {
+	sessionKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	conf := &signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Prefixes:   []string{"TOKEN-", "SESS-"},
+		PrefixKeys: map[string]signedstrings.Keys{"SESS-": {sessionKey}},
+	}
+	compiled := must(conf.Compile())
+	for _, c := range []*signedstrings.Configuration{conf, compiled} {
+		if v, err := c.Validate("TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"); err != nil || v != "foo" {
+			t.Errorf("Validate(TOKEN-) = %q, %v", v, err)
+		}
+		if _, err := c.Validate("SESS-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"); err != signedstrings.InvalidSig {
+			t.Errorf("Validate(SESS- with default key) = %v", err)
+		}
+	}
+	sess := must(compiled.SignWithPrefix("SESS-", "foo"))
+	if v, err := conf.Validate(sess); err != nil || v != "foo" {
+		t.Errorf("Validate(%q) = %q, %v", sess, v, err)
+	}
+
+	conf.PrefixKeys["OTHER-"] = signedstrings.Keys{sessionKey}
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: PrefixKeys has unknown prefix OTHER-" {
+		t.Errorf("Check() = %v", err)
+	}
+	delete(conf.PrefixKeys, "OTHER-")
+	conf.PrefixKeys["SESS-"] = signedstrings.Keys{sessionKey[:4]}
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: short key" {
+		t.Errorf("Check() = %v", err)
+	}
+	conf.Keys = nil
+	conf.PrefixKeys["SESS-"] = signedstrings.Keys{sessionKey}
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: not configured" {
+		t.Errorf("Check() = %v", err)
+	}
+}
+
+func ExampleConfiguration_ValidateWithPrefix() {
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"SESS-", "API-"},
+	}
+	sess := conf.Sign("foo")
+	api := must(conf.SignWithPrefix("API-", "foo"))
+
+	print(conf.ValidateWithPrefix(sess, "SESS-"))
+	print(conf.ValidateWithPrefix(api, "SESS-"))
+	print(conf.ValidateWithPrefix(api, "SESS-", "API-"))
+
+	// Output: foo
+	// err: invalid string
+	// foo
+}
+
+func TestValidateWithPrefix_unknown(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	assertPanic(t, "signedstrings: unknown prefix SESS-", func() {
+		conf.ValidateWithPrefix("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334", "SESS-")
+	})
+}
+
+func ExampleConfiguration_SignWithPrefix_pattern() {
+	conf := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-*-"},
+	}
+	signed, err := conf.SignWithPrefix("TOKEN-eu1-", "foo-bar")
+	fmt.Println(signed, err)
+	fmt.Println(conf.ValidateDetailed(signed))
+	print(conf.Validate("TOKEN--foo-bar" + signed[len("TOKEN-eu1-foo-bar"):]))
+	print(conf.TrySign("foo"))
+	print(conf.SignWithPrefix("TOKEN-eu-1-", "foo"))
+
+	// Output: TOKEN-eu1-foo-bar-ab20664bb57bd32d8d24bd1da171011c3fbbc85820555a1452b9c2126063b70c <nil>
+	// {foo-bar TOKEN-eu1- TOKEN-*- HS256 a814acf20ffba3c8} <nil>
+	// err: invalid string
+	// err: signedstrings: cannot sign with prefix pattern TOKEN-*-, use SignWithPrefix
+	// err: signedstrings: unknown prefix TOKEN-eu-1-
+}
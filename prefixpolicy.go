@@ -0,0 +1,34 @@
+package signedstrings
+
+import "time"
+
+// PrefixPolicy configures the strings with one of the Prefixes, see
+// Configuration.PrefixPolicies. Zero fields keep the settings of the parent
+// configuration.
+type PrefixPolicy struct {
+	// Keys are used instead of the parent keys, like PrefixKeys.
+	Keys Keys
+
+	// MaxAge is the lifetime of the strings; turns on Timestamp.
+	MaxAge time.Duration
+
+	// Algorithms replace the parent algorithms, e.g. HS256 for shorter
+	// signatures.
+	Algorithms []string
+
+	// SigEncodings replace the parent signature encodings.
+	SigEncodings []string
+}
+
+func (p PrefixPolicy) apply(conf *Configuration) {
+	if p.MaxAge > 0 {
+		conf.MaxAge = p.MaxAge
+		conf.Timestamp = true
+	}
+	if len(p.Algorithms) > 0 {
+		conf.Algorithms = p.Algorithms
+	}
+	if len(p.SigEncodings) > 0 {
+		conf.SigEncodings = p.SigEncodings
+	}
+}
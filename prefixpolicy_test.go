@@ -0,0 +1,54 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func Example_prefixPolicies() {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"user-", "reset-"},
+		PrefixPolicies: map[string]signedstrings.PrefixPolicy{
+			"reset-": {MaxAge: time.Hour, SigEncodings: []string{"base64"}},
+		},
+		Sep: ":",
+		Now: func() time.Time { return now },
+	}
+	user := conf.Sign("42")
+	reset, _ := conf.SignWithPrefix("reset-", "42")
+	fmt.Println(user)
+	fmt.Println(reset)
+	print(conf.Validate(user))
+	print(conf.Validate(reset))
+
+	now = now.Add(2 * time.Hour)
+	print(conf.Validate(user))
+	print(conf.Validate(reset))
+
+	// Output: user-42:9acbba33d31c808d2beb6ea1dcc179f1a3778c97be8052b0c576208b3b808669
+	// reset-42:@1700000000.3v5f2eSatWR9L2aL858uGzZXHdqLwjC1wKu3e8uW6E4
+	// 42
+	// 42
+	// 42
+	// err: expired
+}
+
+func TestPrefixPolicies_validationFailedOnce(t *testing.T) {
+	var calls int
+	conf := &signedstrings.Configuration{
+		Keys:             [][]byte{exampleKey},
+		Prefixes:         []string{"user-", "reset-"},
+		PrefixPolicies:   map[string]signedstrings.PrefixPolicy{"reset-": {MaxAge: time.Hour}},
+		RedactErrors:     true,
+		ValidationFailed: func(signed string, err error) { calls++ },
+	}
+	conf.Validate("reset-foo-forged")
+	if calls != 1 {
+		t.Errorf("ValidationFailed called %d times, wanted 1", calls)
+	}
+}
@@ -0,0 +1,106 @@
+package signedstrings
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Purpose configures a kind of token backed by the keys of a parent
+// configuration, see Configuration.Purposes.
+type Purpose struct {
+	// Prefix is added in front of the tokens.
+	Prefix string
+
+	// TTL is the lifetime of new tokens, enforced with a CaveatExpires caveat.
+	// Zero means that tokens do not expire.
+	TTL time.Duration
+}
+
+// SignPurpose signs data as a token of the given purpose, using a key derived
+// from the configured keys and the purpose name, so tokens of one purpose
+// are never accepted as another. Panics if the purpose is not configured.
+func (conf *Configuration) SignPurpose(purpose, data string) (string, error) {
+	sub, err := conf.forPurpose(purpose)
+	if err != nil {
+		return "", err
+	}
+	signed, err := sub.TrySign(data)
+	if err != nil {
+		return "", err
+	}
+	if ttl := conf.Purposes[purpose].TTL; ttl > 0 {
+		return sub.Attenuate(signed, CaveatExpires(conf.now().Add(ttl)))
+	}
+	return signed, nil
+}
+
+// ValidatePurpose validates a token produced by SignPurpose for the same
+// purpose, and returns the original data.
+func (conf *Configuration) ValidatePurpose(purpose, signed string) (string, error) {
+	sub, err := conf.forPurpose(purpose)
+	if err != nil {
+		return "", err
+	}
+	return sub.ValidateCaveats(signed, StandardCaveats(conf.now(), ""))
+}
+
+func (conf *Configuration) forPurpose(purpose string) (*Configuration, error) {
+	p, ok := conf.Purposes[purpose]
+	if !ok {
+		panic("signedstrings: unknown purpose " + purpose)
+	}
+	conf.sanityCheck()
+	if st := conf.compiled(); st != nil {
+		return st.purposeConfs[purpose], nil
+	}
+	return conf.purposeConf(purpose, p)
+}
+
+// purposeConf builds the configuration of a purpose, whose keys are MACs of
+// the purpose name computed with the parent keys. External parent keys are
+// wrapped in purposeKeys instead, so the derived keys are not kept in memory.
+func (conf *Configuration) purposeConf(name string, p Purpose) (*Configuration, error) {
+	keys := conf.keys()
+	if len(keys) == 0 {
+		return nil, errors.New("signedstrings: purposes require secret keys")
+	}
+	label := []byte("signedstrings purpose " + name)
+	if len(conf.ExternalKeys) > 0 {
+		sub := conf.derive(nil, []string{p.Prefix})
+		sub.ExternalKeys = make([]ExternalKey, len(keys))
+		for i, key := range keys {
+			sub.ExternalKeys[i] = purposeKey{key, label}
+		}
+		return sub, nil
+	}
+	derived := make(Keys, len(keys))
+	for i, key := range keys {
+		mac, err := key.MAC(HS256, label)
+		if err != nil {
+			return nil, err
+		}
+		derived[i] = mac
+	}
+	return conf.derive(derived, []string{p.Prefix}), nil
+}
+
+// purposeKey derives the key of a purpose from an external key on every
+// call, and wipes it afterwards.
+type purposeKey struct {
+	parent ExternalKey
+	label  []byte
+}
+
+func (k purposeKey) MAC(alg *Algorithm, message []byte) ([]byte, error) {
+	return k.MACContext(context.Background(), alg, message)
+}
+
+func (k purposeKey) MACContext(ctx context.Context, alg *Algorithm, message []byte) ([]byte, error) {
+	key, err := keyMAC(ctx, k.parent, HS256, k.label)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	return rawKey(key).MAC(alg, message)
+}
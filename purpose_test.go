@@ -0,0 +1,107 @@
+package signedstrings_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignPurpose() {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Purposes: map[string]signedstrings.Purpose{
+			"session": {Prefix: "SESS-"},
+			"reset":   {Prefix: "RST-", TTL: time.Hour},
+		},
+		Now: func() time.Time { return now },
+	}
+
+	sess := must(conf.SignPurpose("session", "42"))
+	reset := must(conf.SignPurpose("reset", "42"))
+	fmt.Println(sess)
+	fmt.Println(reset)
+
+	print(conf.ValidatePurpose("session", sess))
+	print(conf.ValidatePurpose("reset", reset))
+	print(conf.ValidatePurpose("reset", "RST-"+sess[len("SESS-"):]))
+
+	now = now.Add(2 * time.Hour)
+	print(conf.ValidatePurpose("reset", reset))
+
+	// Output: SESS-42-306d8b980547cb82c4c6ca4df4be5ffb2afb0b5dc3021eeeca4c80ff01c79522
+	// RST-42-~expires%3D1700003600.d79e8ce2276b9b2c58f890a9f6d24098a51e30640dd45c738e54caf465d863b5
+	// 42
+	// 42
+	// err: invalid signature
+	// err: unsatisfied caveat: expires=1700003600: expired
+}
+
+func TestPurposes(t *testing.T) {
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Purposes: map[string]signedstrings.Purpose{"session": {Prefix: "SESS-"}},
+	}
+	compiled := must(conf.Compile())
+	sess := must(conf.SignPurpose("session", "42"))
+	if s := must(compiled.SignPurpose("session", "42")); s != sess {
+		t.Errorf("compiled SignPurpose = %q, wanted %q", s, sess)
+	}
+	if _, err := conf.Validate(sess); err != signedstrings.InvalidSig {
+		t.Errorf("Validate(purpose token) = %v", err)
+	}
+	assertPanic(t, "signedstrings: unknown purpose reset", func() {
+		conf.SignPurpose("reset", "42")
+	})
+}
+
+func TestPurposes_externalKeys(t *testing.T) {
+	purposes := map[string]signedstrings.Purpose{"session": {Prefix: "SESS-"}}
+	raw := &signedstrings.Configuration{Keys: [][]byte{exampleKey}, Purposes: purposes}
+	hsm := &fakeHSM{key: exampleKey}
+	conf := &signedstrings.Configuration{ExternalKeys: []signedstrings.ExternalKey{hsm}, Purposes: purposes}
+	compiled := must(conf.Compile())
+
+	sess := must(raw.SignPurpose("session", "42"))
+	for _, c := range []*signedstrings.Configuration{conf, compiled} {
+		if s := must(c.SignPurpose("session", "42")); s != sess {
+			t.Errorf("SignPurpose = %q, wanted %q", s, sess)
+		}
+		if data, err := c.ValidatePurpose("session", sess); err != nil || data != "42" {
+			t.Errorf("ValidatePurpose = %q, %v", data, err)
+		}
+	}
+
+	hsm.down = true
+	if _, err := compiled.ValidatePurpose("session", sess); err == nil || err.Error() != "hsm unavailable" {
+		t.Errorf("ValidatePurpose with the HSM down = %v", err)
+	}
+}
+
+func TestPurposes_derivedSettings(t *testing.T) {
+	var failed []string
+	conf := &signedstrings.Configuration{
+		Keys:             [][]byte{exampleKey},
+		Purposes:         map[string]signedstrings.Purpose{"session": {Prefix: "SESS-"}},
+		Transforms:       []func(string) (string, error){func(s string) (string, error) { return strings.ToUpper(s), nil }},
+		RedactErrors:     true,
+		ValidationFailed: func(signed string, err error) { failed = append(failed, signed) },
+	}
+	sess := must(conf.SignPurpose("session", "abc"))
+	if data, err := conf.ValidatePurpose("session", sess); err != nil || data != "ABC" {
+		t.Errorf("ValidatePurpose = %q, %v, wanted transformed data", data, err)
+	}
+
+	_, err := conf.ValidatePurpose("session", "SESS-abc-forged")
+	var redacted *signedstrings.RedactedError
+	if !errors.As(err, &redacted) {
+		t.Errorf("ValidatePurpose(forged) = %v, wanted a redacted error", err)
+	}
+	if len(failed) != 1 || failed[0] != "SESS-abc-forged" {
+		t.Errorf("ValidationFailed got %q", failed)
+	}
+}
@@ -0,0 +1,71 @@
+package signedstrings
+
+import "errors"
+
+// reasons maps validation errors to their Reason, most specific first.
+var reasons = []struct {
+	err    error
+	reason string
+}{
+	{Expired, "expired"},
+	{RetiredKey, "retired_key"},
+	{ExpiredKey, "expired_key"},
+	{NeedsReissue, "needs_reissue"},
+	{MalformedSig, "malformed_signature"},
+	{InvalidSig, "invalid_signature"},
+	{InvalidAlg, "invalid_algorithm"},
+	{InvalidVersion, "invalid_version"},
+	{UnsatisfiedCaveat, "unsatisfied_caveat"},
+	{Revoked, "revoked"},
+	{Invalid, "invalid"},
+}
+
+// Reason classifies a validation error for logs and metrics without
+// revealing anything about the string: valid (for nil), expired,
+// retired_key, expired_key, needs_reissue, malformed_signature, invalid_signature,
+// invalid_algorithm, invalid_version, unsatisfied_caveat, revoked, invalid,
+// or error
+// for failures other than validation, e.g. of external keys.
+func Reason(err error) string {
+	if err == nil {
+		return "valid"
+	}
+	for _, r := range reasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "error"
+}
+
+// RedactedError replaces validation errors when RedactErrors is set.
+// It matches the same sentinel errors (Invalid, InvalidSig, Expired etc.)
+// with errors.Is, but carries only the Reason and the Fingerprint of the
+// string.
+type RedactedError struct {
+	Reason      string
+	Fingerprint string
+	err         error // the sentinel, nil for failures other than validation
+}
+
+func (e *RedactedError) Error() string {
+	msg := "signedstrings: transform failed"
+	if e.err != nil {
+		msg = e.err.Error()
+	}
+	return msg + " (fingerprint " + e.Fingerprint + ")"
+}
+
+func (e *RedactedError) Unwrap() error { return e.err }
+
+// redact returns a RedactedError for a validation error, see RedactErrors.
+func redact(signed string, err error) error {
+	e := &RedactedError{Reason: "error", Fingerprint: Fingerprint(signed)}
+	for _, r := range reasons {
+		if errors.Is(err, r.err) {
+			e.Reason, e.err = r.reason, r.err
+			break
+		}
+	}
+	return e
+}
@@ -0,0 +1,50 @@
+// Package redisnonce provides a signedstrings.NonceStore backed by Redis,
+// so that single-use tokens and replay protection work across a fleet of
+// instances rather than in one process.
+package redisnonce
+
+import (
+	"context"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is prepended to nonces to form Redis keys.
+const DefaultPrefix = "signedstrings:nonce:"
+
+// minTTL keeps nonces that are about to expire, or already have, for a moment:
+// Redis rejects zero and negative expirations.
+const minTTL = time.Second
+
+// Store is a signedstrings.ContextNonceStore that records each nonce with
+// SET NX and an expiration, so Redis forgets it once it expires.
+type Store struct {
+	// Client is a *redis.Client, *redis.ClusterClient or *redis.Ring.
+	Client redis.Cmdable
+
+	// Prefix is prepended to nonces to form keys. Defaults to DefaultPrefix.
+	Prefix string
+}
+
+var _ signedstrings.ContextNonceStore = (*Store)(nil)
+
+func (s *Store) Use(nonce string, expires time.Time) (bool, error) {
+	return s.UseContext(context.Background(), nonce, expires)
+}
+
+func (s *Store) UseContext(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	ttl := time.Until(expires)
+	if ttl < minTTL {
+		ttl = minTTL
+	}
+	return s.Client.SetNX(ctx, s.prefix()+nonce, 1, ttl).Result()
+}
+
+func (s *Store) prefix() string {
+	if s.Prefix != "" {
+		return s.Prefix
+	}
+	return DefaultPrefix
+}
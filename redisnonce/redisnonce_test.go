@@ -0,0 +1,36 @@
+package redisnonce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/andreyvit/signedstrings/redisnonce"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	store := &redisnonce.Store{Client: redis.NewClient(&redis.Options{Addr: srv.Addr()})}
+
+	expires := time.Now().Add(time.Minute)
+	for i, want := range []bool{true, false} {
+		ok, err := store.Use("n1", expires)
+		if err != nil {
+			t.Fatal(err)
+		} else if ok != want {
+			t.Errorf("Use #%d = %v, wanted %v", i+1, ok, want)
+		}
+	}
+	if !srv.Exists(redisnonce.DefaultPrefix + "n1") {
+		t.Errorf("key %s not set", redisnonce.DefaultPrefix+"n1")
+	}
+
+	srv.FastForward(2 * time.Minute)
+	if ok, err := store.Use("n1", time.Now().Add(time.Minute)); err != nil || !ok {
+		t.Errorf("Use after expiry = %v, %v", ok, err)
+	}
+	if ok, err := store.Use("n2", time.Now().Add(-time.Minute)); err != nil || !ok {
+		t.Errorf("Use of an expired nonce = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,201 @@
+package signedstrings
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemoteKeySet validates strings using public keys fetched from a KeySet
+// published by the issuer (see KeySetHandler), so that verifying services
+// pick up key rotations without redeploys.
+//
+// The key set is cached for TTL and revalidated with its ETag. A string that
+// fails signature validation triggers an early refresh, since it may have been
+// signed with a new key; refreshes happen at most once per MinRefresh.
+// When a refresh fails, the previously fetched keys remain in use.
+type RemoteKeySet struct {
+	// URL of the published KeySet.
+	URL string
+
+	// Conf, if set, holds the settings of the issuer, e.g. Prefixes; its keys
+	// are not used. Algorithms, if set, restricts the published algorithms; otherwise
+	// all published asymmetric algorithms are accepted.
+	Conf *Configuration
+
+	// Client defaults to one with a 10 second timeout.
+	Client *http.Client
+
+	// TTL defaults to 10 minutes, MinRefresh to 1 minute.
+	TTL        time.Duration
+	MinRefresh time.Duration
+
+	mu        sync.Mutex
+	conf      *Configuration
+	etag      string
+	fetchedAt time.Time
+	fetching  chan struct{} // closed when the fetch in progress completes
+}
+
+var defaultKeySetClient = &http.Client{Timeout: 10 * time.Second}
+
+// Validate is Configuration.Validate with the remote keys.
+func (rk *RemoteKeySet) Validate(signed string) (string, error) {
+	return rk.ValidateContext(context.Background(), signed)
+}
+
+// ValidateContext is like Validate, but fetches the key set with ctx.
+func (rk *RemoteKeySet) ValidateContext(ctx context.Context, signed string) (string, error) {
+	conf, err := rk.current(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	data, err := conf.ValidateContext(ctx, signed)
+	if errors.Is(err, InvalidSig) {
+		if fresh, ferr := rk.current(ctx, true); ferr == nil && fresh != conf {
+			return fresh.ValidateContext(ctx, signed)
+		}
+	}
+	return data, err
+}
+
+// current returns the configuration for the cached keys, fetching them first
+// if they are stale, or if force is set and MinRefresh has passed. Only one
+// fetch runs at a time; others keep using the cached keys meanwhile, or wait
+// for it if there are none or force is set.
+func (rk *RemoteKeySet) current(ctx context.Context, force bool) (*Configuration, error) {
+	rk.mu.Lock()
+	for {
+		base := rk.base()
+		age := base.now().Sub(rk.fetchedAt)
+		ttl, minRefresh := rk.TTL, rk.MinRefresh
+		if ttl == 0 {
+			ttl = 10 * time.Minute
+		}
+		if minRefresh == 0 {
+			minRefresh = time.Minute
+		}
+		if rk.conf != nil && age < ttl && !(force && age >= minRefresh) {
+			conf := rk.conf
+			rk.mu.Unlock()
+			return conf, nil
+		}
+		if rk.fetching == nil {
+			break
+		} else if rk.conf != nil && !force {
+			conf := rk.conf
+			rk.mu.Unlock()
+			return conf, nil
+		}
+		fetching := rk.fetching
+		rk.mu.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		rk.mu.Lock()
+		if rk.conf != nil {
+			conf := rk.conf
+			rk.mu.Unlock()
+			return conf, nil
+		}
+	}
+
+	fetching := make(chan struct{})
+	rk.fetching = fetching
+	etag := rk.etag
+	if rk.conf == nil {
+		etag = ""
+	}
+	rk.mu.Unlock()
+
+	conf, etag, err := rk.fetch(ctx, etag)
+
+	rk.mu.Lock()
+	defer rk.mu.Unlock()
+	if conf != nil {
+		rk.conf, rk.etag = conf, etag
+	}
+	rk.fetchedAt = rk.base().now()
+	rk.fetching = nil
+	close(fetching)
+	if err != nil && rk.conf == nil {
+		return nil, err
+	}
+	return rk.conf, nil
+}
+
+// fetch downloads the key set, sending etag if non-empty. It returns a nil
+// configuration if the key set has not been modified.
+func (rk *RemoteKeySet) fetch(ctx context.Context, etag string) (*Configuration, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rk.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	client := rk.Client
+	if client == nil {
+		client = defaultKeySetClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, "", nil
+	case http.StatusOK:
+	default:
+		return nil, "", fmt.Errorf("signedstrings: fetching key set: %s", resp.Status)
+	}
+	var ks KeySet
+	if err := json.NewDecoder(resp.Body).Decode(&ks); err != nil {
+		return nil, "", fmt.Errorf("signedstrings: fetching key set: %w", err)
+	}
+	conf, err := rk.configure(&ks)
+	if err != nil {
+		return nil, "", err
+	}
+	return conf, resp.Header.Get("ETag"), nil
+}
+
+func (rk *RemoteKeySet) configure(ks *KeySet) (*Configuration, error) {
+	base := rk.base()
+	conf := base.derive(nil, base.Prefixes)
+	published := conf.Algorithms == nil
+	for _, pk := range ks.Keys {
+		alg := LookupAlgorithm(pk.Algorithm)
+		if alg == nil || !alg.IsAsymmetric() || pk.Key == "" {
+			continue // unknown algorithms and HMAC key IDs
+		}
+		pub, err := base64.RawURLEncoding.DecodeString(pk.Key)
+		if err != nil || KeyID(pub) != pk.KeyID {
+			return nil, errors.New("signedstrings: invalid key " + pk.KeyID + " in key set")
+		}
+		conf.PublicKeys = append(conf.PublicKeys, pub)
+		if published && !containsString(conf.Algorithms, alg.Name) {
+			conf.Algorithms = append(conf.Algorithms, alg.Name)
+		}
+	}
+	if len(conf.PublicKeys) == 0 {
+		return nil, errors.New("signedstrings: no usable keys in key set")
+	}
+	return conf.Compile()
+}
+
+func (rk *RemoteKeySet) base() *Configuration {
+	if rk.Conf == nil {
+		return &Configuration{}
+	}
+	return rk.Conf
+}
@@ -0,0 +1,127 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestRemoteKeySet(t *testing.T) {
+	issuer := &signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"Ed25519"},
+		Prefixes:   []string{"TOKEN-"},
+	}
+	var fetches, notModified atomic.Int32
+	handler := issuer.KeySetHandler(60)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if r.Header.Get("If-None-Match") != "" {
+			notModified.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	rk := &signedstrings.RemoteKeySet{
+		URL: srv.URL,
+		Conf: &signedstrings.Configuration{
+			Prefixes: []string{"TOKEN-"},
+			Now:      func() time.Time { return now },
+		},
+	}
+	validate := func(signed, want string) {
+		t.Helper()
+		data, err := rk.Validate(signed)
+		if want == "" {
+			if err == nil {
+				t.Errorf("Validate(%q) = %q, wanted an error", signed, data)
+			}
+		} else if err != nil || data != want {
+			t.Errorf("Validate(%q) = %q, %v, wanted %q", signed, data, err, want)
+		}
+	}
+
+	validate(issuer.Sign("foo"), "foo")
+	validate(issuer.Sign("bar"), "bar")
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, wanted 1", n)
+	}
+
+	// rotation: not picked up until MinRefresh passes
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	issuer.Keys = [][]byte{newKey, exampleKey}
+	validate(issuer.Sign("baz"), "")
+	now = now.Add(time.Minute)
+	validate(issuer.Sign("baz"), "baz")
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, wanted 2", n)
+	}
+
+	// TTL expiry revalidates with the ETag
+	now = now.Add(10 * time.Minute)
+	validate(issuer.Sign("qux"), "qux")
+	if n := notModified.Load(); n != 2 {
+		t.Errorf("conditional fetches = %d, wanted 2", n)
+	}
+
+	// a failing endpoint keeps the cached keys
+	srv.Close()
+	now = now.Add(time.Hour)
+	validate(issuer.Sign("qux"), "qux")
+}
+
+func TestRemoteKeySet_slowFetch(t *testing.T) {
+	issuer := &signedstrings.Configuration{Keys: [][]byte{exampleKey}, Algorithms: []string{"Ed25519"}}
+	handler := issuer.KeySetHandler(60)
+	var fetches atomic.Int32
+	entered, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			close(entered)
+			<-release
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	rk := &signedstrings.RemoteKeySet{URL: srv.URL, Conf: &signedstrings.Configuration{Now: func() time.Time { return now }}}
+	signed := issuer.Sign("foo")
+	if _, err := rk.Validate(signed); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		rk.Validate(signed)
+	}()
+	<-entered
+
+	done := make(chan error)
+	go func() {
+		_, err := rk.Validate(signed)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Validate during refresh = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Validate blocked by the refresh in progress")
+	}
+	close(release)
+	<-refreshed
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, wanted 2", n)
+	}
+}
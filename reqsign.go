@@ -0,0 +1,181 @@
+package signedstrings
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying request signatures, see RequestSigner.
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+// StaleRequest is the error returned for signed requests whose timestamp
+// is outside of the tolerated window.
+var StaleRequest = errors.New("stale request")
+
+// RequestSigner is an http.RoundTripper that signs outgoing requests,
+// covering the timestamp, method, path and query, the selected Headers, and
+// the SHA-256 digest of the body. Verify on the server with RequestVerifier.
+type RequestSigner struct {
+	Conf *Configuration
+
+	// Headers are the names of additional headers to cover, e.g. Content-Type.
+	// "Host" covers the request host.
+	Headers []string
+
+	// Transport sends the signed requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+func (rs *RequestSigner) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(r, -1)
+	if err != nil {
+		return nil, err
+	}
+	r = r.Clone(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	ts := strconv.FormatInt(rs.Conf.now().Unix(), 10)
+	sig, err := rs.Conf.signDetached(canonicalRequest(r, ts, rs.Headers, body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set(TimestampHeader, ts)
+	r.Header.Set(SignatureHeader, sig)
+
+	t := rs.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return t.RoundTrip(r)
+}
+
+// RequestVerifier checks signatures made by RequestSigner.
+type RequestVerifier struct {
+	Conf *Configuration
+
+	// Headers must match RequestSigner.Headers.
+	Headers []string
+
+	// Tolerance is the maximum difference between the request timestamp and
+	// the current time. Defaults to 5 minutes.
+	Tolerance time.Duration
+
+	// MaxBodySize limits how much of the body is read for hashing.
+	// Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Verify checks the signature of the request. The body is read and replaced
+// with an in-memory copy.
+func (rv *RequestVerifier) Verify(r *http.Request) error {
+	ts, sig := r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader)
+	if ts == "" || sig == "" {
+		return Invalid
+	}
+	if err := checkRequestTime(rv.Conf, ts, rv.Tolerance); err != nil {
+		return err
+	}
+
+	maxSize := rv.MaxBodySize
+	if maxSize == 0 {
+		maxSize = 10 << 20
+	}
+	body, err := readRequestBody(r, maxSize)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return rv.Conf.validateDetached(canonicalRequest(r, ts, rv.Headers, body), sig)
+}
+
+// Middleware rejects requests that fail Verify with 401 Unauthorized.
+func (rv *RequestVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := rv.Verify(r)
+		if isValidationError(err) || errors.Is(err, StaleRequest) {
+			http.Error(w, "invalid request signature", http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func canonicalRequest(r *http.Request, ts string, headers []string, body []byte) string {
+	var buf strings.Builder
+	buf.WriteString(ts)
+	buf.WriteByte('\n')
+	buf.WriteString(r.Method)
+	buf.WriteByte('\n')
+	buf.WriteString(r.URL.RequestURI())
+	buf.WriteByte('\n')
+	for _, name := range headers {
+		var value string
+		if strings.EqualFold(name, "Host") {
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		} else {
+			value = strings.Join(r.Header.Values(name), ", ")
+		}
+		buf.WriteString(strings.ToLower(name))
+		buf.WriteByte(':')
+		buf.WriteString(strings.TrimSpace(value))
+		buf.WriteByte('\n')
+	}
+	digest := sha256.Sum256(body)
+	buf.WriteString(hex.EncodeToString(digest[:]))
+	return buf.String()
+}
+
+// checkRequestTime checks a TimestampHeader value against the current time,
+// with a default tolerance of 5 minutes.
+func checkRequestTime(conf *Configuration, ts string, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Invalid
+	}
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if d := conf.now().Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return StaleRequest
+	}
+	return nil
+}
+
+// readRequestBody reads at most maxSize bytes of the body (no limit if
+// negative), and closes it.
+func readRequestBody(r *http.Request, maxSize int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	defer r.Body.Close()
+	var body io.Reader = r.Body
+	if maxSize >= 0 {
+		body = io.LimitReader(r.Body, maxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize >= 0 && int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("request body exceeds %d bytes", maxSize)
+	}
+	return data, nil
+}
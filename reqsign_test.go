@@ -0,0 +1,69 @@
+package signedstrings_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestRequestSigning(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	verifier := &signedstrings.RequestVerifier{Conf: conf, Headers: []string{"Content-Type", "Host"}}
+	srv := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &signedstrings.RequestSigner{Conf: conf, Headers: []string{"Content-Type", "Host"}}}
+	post := func(client *http.Client, body string) (int, string) {
+		resp, err := client.Post(srv.URL+"/hook?x=1", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	if code, body := post(client, `{"a":1}`); code != 200 || body != `{"a":1}` {
+		t.Errorf("signed request: %d %q", code, body)
+	}
+	if code, _ := post(http.DefaultClient, `{"a":1}`); code != 401 {
+		t.Errorf("unsigned request: %d", code)
+	}
+
+	tampering := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.Body = io.NopCloser(strings.NewReader(`{"a":2}`))
+		r.ContentLength = 7
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	client.Transport.(*signedstrings.RequestSigner).Transport = tampering.Transport
+	if code, _ := post(client, `{"a":1}`); code != 401 {
+		t.Errorf("tampered request: %d", code)
+	}
+	client.Transport.(*signedstrings.RequestSigner).Transport = nil
+
+	client.Transport.(*signedstrings.RequestSigner).Conf = &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now.Add(-10 * time.Minute) },
+	}
+	if code, _ := post(client, `{"a":1}`); code != 401 {
+		t.Errorf("stale request: %d", code)
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
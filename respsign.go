@@ -0,0 +1,93 @@
+package signedstrings
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ResponseSignatureHeader carries response signatures, see SignResponses.
+const ResponseSignatureHeader = "X-Response-Signature"
+
+// SignResponses is middleware that signs response bodies, so that clients
+// can detect responses modified by intermediaries or corrupted caches via
+// VerifyResponse. The signature covers the request method and URI, the status
+// code and the SHA-256 digest of the body.
+//
+// Responses are buffered in memory to put the signature into a header, so
+// this is not suitable for streaming responses.
+func (conf *Configuration) SignResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bw := &bufferingWriter{header: make(http.Header)}
+		next.ServeHTTP(bw, r)
+		if bw.code == 0 {
+			bw.code = http.StatusOK
+		}
+
+		sig, err := conf.signDetached(canonicalResponse(r.Method, r.URL.RequestURI(), bw.code, bw.body.Bytes()))
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		h := w.Header()
+		for k, v := range bw.header {
+			h[k] = v
+		}
+		h.Set(ResponseSignatureHeader, sig)
+		h.Set("Content-Length", strconv.Itoa(bw.body.Len()))
+		w.WriteHeader(bw.code)
+		w.Write(bw.body.Bytes())
+	})
+}
+
+// VerifyResponse checks the signature added by SignResponses. The body is
+// read and replaced with an in-memory copy.
+func (conf *Configuration) VerifyResponse(resp *http.Response) error {
+	sig := resp.Header.Get(ResponseSignatureHeader)
+	if sig == "" {
+		return Invalid
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	req := resp.Request
+	if req == nil {
+		return Invalid
+	}
+	return conf.validateDetached(canonicalResponse(req.Method, req.URL.RequestURI(), resp.StatusCode, body), sig)
+}
+
+func canonicalResponse(method, uri string, code int, body []byte) string {
+	digest := sha256.Sum256(body)
+	return method + "\n" + uri + "\n" + strconv.Itoa(code) + "\n" + hex.EncodeToString(digest[:])
+}
+
+type bufferingWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *bufferingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferingWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *bufferingWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.body.Write(b)
+}
@@ -0,0 +1,43 @@
+package signedstrings_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestResponseSigning(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	srv := httptest.NewServer(conf.SignResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(201)
+		io.WriteString(w, "hello")
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conf.VerifyResponse(resp); err != nil {
+		t.Fatalf("VerifyResponse: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 201 || string(body) != "hello" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("got %d %q %q", resp.StatusCode, body, resp.Header.Get("Content-Type"))
+	}
+
+	resp.Body = io.NopCloser(strings.NewReader("HELLO"))
+	if err := conf.VerifyResponse(resp); err != signedstrings.InvalidSig {
+		t.Errorf("VerifyResponse of modified body = %v", err)
+	}
+	resp.Body = io.NopCloser(strings.NewReader("hello"))
+	resp.StatusCode = 200
+	if err := conf.VerifyResponse(resp); err != signedstrings.InvalidSig {
+		t.Errorf("VerifyResponse of modified status = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package signedstrings
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Revoked is returned for correctly signed strings that have been revoked,
+// see Configuration.Revocations.
+var Revoked = fmt.Errorf("%w: revoked", Invalid)
+
+// RevocationChecker tells which strings have been revoked before they
+// expire. Strings are identified by their RevocationFingerprint, so the
+// store never holds usable tokens. See package sqlrevocation for
+// a database/sql implementation.
+type RevocationChecker interface {
+	// RevokedAmong returns which of the given fingerprints are revoked.
+	RevokedAmong(ctx context.Context, fingerprints []string) (map[string]bool, error)
+}
+
+// RevocationFingerprint returns the fingerprint identifying signed to
+// a RevocationChecker. Unlike Fingerprint, it covers the prefix, data,
+// fields, caveats and the decoded signature rather than their spelling, so
+// a revoked string stays revoked when re-spelled with another of Seps or
+// SigEncodings. The signature is not verified.
+func (conf *Configuration) RevocationFingerprint(signed string) (string, error) {
+	conf.sanityCheck()
+	tok, ok := conf.parse(signed)
+	if !ok {
+		return "", Invalid
+	}
+	if _, _, idx := conf.cutPrefix(tok.msg); idx < 0 {
+		return "", Invalid
+	} else if sub := conf.forPrefix(conf.prefixes()[idx]); sub != conf {
+		return sub.RevocationFingerprint(signed)
+	}
+	if conf.isLegacySHA1(tok) {
+		tok.alg = legacySHA1
+	} else if tok.alg == nil {
+		return "", InvalidAlg
+	}
+	encs := conf.wellFormedEncodings(tok)
+	if len(encs) == 0 {
+		return "", MalformedSig
+	}
+	return revocationFingerprint(tok, encs[0])
+}
+
+func revocationFingerprint(tok token, enc *macEncoding) (string, error) {
+	mac, err := enc.decode(tok.mac)
+	if err != nil {
+		return "", MalformedSig
+	}
+	var buf strings.Builder
+	for _, part := range append([]string{tok.msg, tok.fields, string(mac)}, tok.caveats...) {
+		buf.WriteString(strconv.Itoa(len(part)))
+		buf.WriteString(":")
+		buf.WriteString(part)
+	}
+	return Fingerprint(buf.String()), nil
+}
+
+func checkRevoked(ctx context.Context, checker RevocationChecker, tok token, enc *macEncoding) error {
+	fp, err := revocationFingerprint(tok, enc)
+	if err != nil {
+		return err
+	}
+	revoked, err := checker.RevokedAmong(ctx, []string{fp})
+	if err != nil {
+		return err
+	} else if revoked[fp] {
+		return Revoked
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package signedstrings_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+type revokedSet map[string]bool
+
+func (s revokedSet) RevokedAmong(ctx context.Context, fingerprints []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, fp := range fingerprints {
+		result[fp] = s[fp]
+	}
+	return result, nil
+}
+
+func ExampleRevocationChecker() {
+	revoked := revokedSet{}
+	conf := signedstrings.Configuration{
+		Keys:         [][]byte{exampleKey},
+		Sep:          ":",
+		SigEncodings: []string{"hex", "base64"},
+		Revocations:  revoked,
+	}
+	signed := conf.Sign("foo")
+	print(conf.Validate(signed))
+	revoked[must(conf.RevocationFingerprint(signed))] = true
+	print(conf.Validate(signed))
+	respelled := "foo:" + base64.RawURLEncoding.EncodeToString(must(hex.DecodeString(signed[4:])))
+	print(conf.Validate(respelled))
+
+	// Output: foo
+	// err: invalid string: revoked
+	// err: invalid string: revoked
+}
@@ -1,13 +1,18 @@
 package signedstrings
 
 import (
-	"crypto/hmac"
+	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type Configuration struct {
@@ -15,17 +20,220 @@ type Configuration struct {
 	// when signing new messages. Multiple valid keys allow for key rotation.
 	Keys Keys
 
+	// ExternalKeys replace Keys when the keys live in an HSM or a KMS.
+	// Same rotation rules apply: the first one signs, all are accepted.
+	// Cannot be combined with Keys.
+	ExternalKeys []ExternalKey
+
+	// PublicKeys are accepted when validating signatures made with asymmetric
+	// algorithms, in addition to the public halves of Keys. A configuration
+	// with PublicKeys only is verify-only: it validates, but refuses to sign,
+	// so it can be handed to parties that must not be able to forge strings.
+	PublicKeys Keys
+
 	// Prefixes are added in front of the tokens to help identify them.
 	// The first one is used for new tokens. Others are accepted when
 	// validating tokens to allow prefix changes.
 	//
 	// An empty prefix is a valid choice. Omitting this field is the same as
 	// specifying a single empty prefix.
+	//
+	// A prefix can be a pattern like TOKEN-*-, where each * stands for one or
+	// more characters other than the first character following it, to accept
+	// prefixes carrying variable metadata such as a tenant or region.
+	// Patterns are matched in variable time. Use SignWithPrefix to sign with
+	// a concrete prefix; ValidateDetailed returns the one that matched.
 	Prefixes []string
 
 	// Sep is the separator between the data and the signature, a cosmetic choice.
 	// Defaults to a dash.
 	Sep string
+
+	// Seps replaces Sep to allow separator changes, like Prefixes: the first
+	// one is used for new tokens, others are accepted when validating.
+	// Cannot be combined with Sep.
+	Seps []string
+
+	// FixedLength omits the separator: the signature is the fixed-length
+	// tail of signed strings, e.g. foo1c54..., for systems that forbid
+	// punctuation in identifiers. Requires a single HMAC algorithm and
+	// signature encoding; Sep, Timestamp, Salt, version markers and caveats
+	// are not supported.
+	FixedLength bool
+
+	// SigFirst puts the signature in front of the data, e.g. 1c54...-foo,
+	// for downstream systems that truncate long values, so that truncation
+	// is noticed without reading the entire string. The signature is the
+	// same as in the default layout. Caveats are not supported.
+	SigFirst bool
+
+	// Algorithms are the names of registered algorithms accepted when
+	// validating signatures. The first one is used when signing new messages.
+	// A message is never validated using an algorithm not on this list, so
+	// a tampered algorithm tag cannot downgrade validation.
+	//
+	// Strings signed with DefaultAlgorithm carry no algorithm tag; other
+	// algorithms add a tag in front of the signature, e.g. foo-HS512.1c54...
+	// Omitting this field is the same as specifying DefaultAlgorithm only.
+	Algorithms []string
+
+	// Versions are the wire format versions accepted when validating. The
+	// first one is used when signing new messages. Version 1, the original
+	// format, carries no marker; later versions add one in front of other
+	// fields, e.g. foo-!2.1c54... Listing several versions lets a format
+	// change roll out gradually, and dropping one sunsets its strings.
+	// Omitting this field is the same as specifying version 1 only.
+	Versions []int
+
+	// FIPS restricts Algorithms to FIPS-approved primitives. A configuration
+	// listing any other algorithm fails Check (and panics when used).
+	FIPS bool
+
+	// PrefixKeys maps some of the Prefixes to their own keys, used instead of
+	// Keys for strings with those prefixes, so one configuration can serve
+	// several token classes without accepting one class in place of another.
+	// Keys may be omitted if all prefixes have their own keys.
+	PrefixKeys map[string]Keys
+
+	// PrefixPolicies maps some of the Prefixes to settings applied to
+	// strings with those prefixes, so that the policy of each token class
+	// is configured in one place instead of at every call site.
+	PrefixPolicies map[string]PrefixPolicy
+
+	// Normalize, if set, is applied to data before signing and to signed
+	// strings before validation. Set it to norm.NFC.String from
+	// golang.org/x/text/unicode/norm, so that visually identical strings
+	// produced by different clients (e.g. macOS NFD file names) validate.
+	Normalize func(s string) string
+
+	// ASCIIOnly rejects data and signed strings containing anything but
+	// printable ASCII, for tokens that travel through legacy systems which
+	// mangle other characters. Signing such data fails instead of producing
+	// a token that would not survive the trip.
+	ASCIIOnly bool
+
+	// RejectEmpty refuses to sign empty data and rejects validly signed
+	// strings with empty data, which are almost always a caller bug.
+	RejectEmpty bool
+
+	// CheckData, if set, is called with the data of validly signed strings
+	// before returning it, to enforce its format (UUID shape, length, charset)
+	// in one place. An error rejects the string, wrapped in Invalid.
+	CheckData func(data string) error
+
+	// Transforms are applied in order to the data of validated strings, e.g.
+	// to decompress, decode or map legacy IDs, so that callers receive the
+	// final value. Errors are returned as is. Inspect, Introspect and
+	// Transcode see the data as signed.
+	Transforms []func(data string) (string, error)
+
+	// RedactErrors replaces validation errors, and errors of Transforms and
+	// CheckData, with a *RedactedError carrying only the reason and the
+	// Fingerprint of the string, for applications that show error strings
+	// to clients or send them to third-party error trackers. Errors still
+	// match Invalid, InvalidSig, Expired etc. with errors.Is, but not
+	// *ExpiredError with errors.As, since it carries the data.
+	RedactErrors bool
+
+	// Revocations, if set, is asked whether validly signed strings have been
+	// revoked; revoked ones are rejected with Revoked. Errors of the checker
+	// are returned as is.
+	Revocations RevocationChecker
+
+	// Purposes configures kinds of tokens backed by Keys or ExternalKeys, see
+	// SignPurpose. Purpose keys derived from ExternalKeys are computed by the
+	// external key on every call and are not retained.
+	Purposes map[string]Purpose
+
+	// AcceptUntil maps key IDs (see KeyID) to deadlines after which strings
+	// signed with those keys are rejected, even if the keys are still listed.
+	// This turns removal of an old key into a scheduled, enforced event.
+	AcceptUntil map[string]time.Time
+
+	// KeyExpiry maps key IDs (see KeyID) to the times the keys expire.
+	// Signing with an expired key fails, so a forgotten rotation becomes a
+	// visible error. Strings signed with expired keys are still accepted
+	// unless RejectExpiredKeys is set.
+	KeyExpiry map[string]time.Time
+
+	// RejectExpiredKeys rejects strings signed with keys past their
+	// KeyExpiry with ExpiredKey.
+	RejectExpiredKeys bool
+
+	// KeyExpiring, if set, is called when signing with a key that expires
+	// within KeyExpiryWarning (see KeyExpiry), so that someone rotates it
+	// before signing starts failing. It is called on every such signature,
+	// so throttle alerts, e.g. with a sync.Once per key ID.
+	KeyExpiring func(keyID string, expires time.Time)
+
+	// KeyExpiryWarning is how long before KeyExpiry to call KeyExpiring.
+	KeyExpiryWarning time.Duration
+
+	// KeyUsed, if set, is called after each successful validation with the ID
+	// (see KeyID) and index of the key that validated the string, e.g. to tell
+	// when traffic signed with an old key has drained. See KeyUsage.
+	KeyUsed func(keyID string, index int)
+
+	// ValidationFailed, if set, is called with strings that fail validation
+	// and the error, e.g. to log them; see LogTo. Strings are attacker
+	// input, so log their Fingerprint, not the strings themselves.
+	ValidationFailed func(signed string, err error)
+
+	// KeysRotated, if set, is called with the IDs (see KeyID) of the new keys
+	// after SetKeys or Rotate changes the keys of a compiled configuration.
+	KeysRotated func(keyIDs []string)
+
+	// QR restricts signed strings to the QR code alphanumeric character set
+	// (uppercase letters, digits and " $%*+-./:"), so that they encode into
+	// much smaller QR codes, e.g. for tickets: signatures are base32-encoded,
+	// and signing data outside of the set fails. Prefixes and Sep must be
+	// in the set too; Timestamp, Salt and caveats are not supported.
+	QR bool
+
+	// Environment, e.g. "staging" or "production", is mixed into signatures
+	// without appearing in signed strings, so that a string signed in one
+	// environment never validates in another, even if keys were shared by
+	// accident. Unlike Prefixes, it is not visible to holders. Changing it
+	// invalidates all outstanding strings.
+	Environment string
+
+	// LegacySHA1Until, if set, accepts untagged HMAC-SHA1 signatures made
+	// with Keys by older signers, e.g. foo-<40 hex digits>, until the given
+	// time, so that strings in flight survive a migration. Such strings
+	// must be re-issued: Validate rejects them with NeedsReissue, and
+	// Transcode returns a replacement signed in the current format.
+	LegacySHA1Until time.Time
+
+	// SigEncodings are the names of signature encodings accepted when
+	// validating: "hex" or "base64" (URL-safe and unpadded, a third shorter,
+	// but needs a separator outside of its alphabet, e.g. ":"). The first
+	// one is used when signing new messages, so the encoding can be changed
+	// without invalidating outstanding strings: list the new one first, and
+	// drop the old one once they have expired. Omitting this field is the
+	// same as specifying "hex" only.
+	SigEncodings []string
+
+	// Timestamp adds the signing time to new strings, e.g. foo-@1700000000.1c54...,
+	// so that validators can enforce MaxAge.
+	Timestamp bool
+
+	// Salt adds a random field to new strings, e.g. foo-_9f86d081....1c54...,
+	// so that signing the same data twice yields different strings that
+	// third parties cannot correlate. Validation accepts strings with and
+	// without salt regardless of this setting.
+	Salt bool
+
+	// MaxAge, if set, rejects strings signed longer ago than that with
+	// an *ExpiredError, and strings without a timestamp (see Timestamp) as
+	// invalid. The validator rather than the signer decides how old a string
+	// may be, so the policy can be tightened without re-issuing strings.
+	MaxAge time.Duration
+
+	// Now returns the current time when checking expiration. Defaults to
+	// time.Now; override in tests.
+	Now func() time.Time
+
+	state *atomic.Pointer[compiledState] // set by Compile
 }
 
 var (
@@ -34,6 +242,22 @@ var (
 	// InvalidSig is the error returned for correctly formatted messages that
 	// fail signature validation (i.e. have been corrupted or tampered with).
 	InvalidSig = errors.New("invalid signature")
+	// InvalidAlg is the error returned for messages signed with an algorithm
+	// that is not on the configured allow-list.
+	InvalidAlg = errors.New("unacceptable algorithm")
+	// RetiredKey is the error returned for messages signed with a key past
+	// its AcceptUntil deadline. It wraps InvalidSig.
+	RetiredKey = fmt.Errorf("%w: key retired", InvalidSig)
+	// InvalidVersion is the error returned for messages in a wire format
+	// version that is not on the configured list, see Versions.
+	InvalidVersion = errors.New("unacceptable format version")
+	// MalformedSig is the error returned for messages whose signature has the
+	// wrong length or encoding, rejected before computing any MAC.
+	// It wraps InvalidSig.
+	MalformedSig = fmt.Errorf("%w: malformed", InvalidSig)
+	// ExpiredKey is the error returned for messages signed with a key past
+	// its KeyExpiry if RejectExpiredKeys is set. It wraps InvalidSig.
+	ExpiredKey = fmt.Errorf("%w: key expired", InvalidSig)
 )
 
 // Minimum acceptable length of secure **fully random** keys.
@@ -41,69 +265,762 @@ var (
 var MinKeyLen = 32
 
 // Sign signs the given string (and adds a configured prefix if any).
+// Panics if signing fails: an external key fails, the key is past its
+// KeyExpiry, or the data is rejected by ASCIIOnly, RejectEmpty or QR. Use
+// TrySign to handle such errors.
 func (conf *Configuration) Sign(data string) string {
+	signed, err := conf.TrySign(data)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// TrySign is like Sign, but returns an error if signing fails.
+func (conf *Configuration) TrySign(data string) (string, error) {
+	conf.sanityCheck()
+	return conf.signWithPrefix(conf.prefixes()[0], data)
+}
+
+// SignContext is like TrySign, but passes ctx to external keys implementing
+// ContextExternalKey.
+func (conf *Configuration) SignContext(ctx context.Context, data string) (string, error) {
 	conf.sanityCheck()
+	return conf.signAt(ctx, conf.prefixes()[0], data, time.Time{})
+}
+
+// WriteSign writes the signed string to w without building it in memory
+// first, e.g. when generating large documents or HTTP responses. Nothing is
+// written if signing fails.
+func (conf *Configuration) WriteSign(w io.Writer, data string) (int, error) {
+	conf.sanityCheck()
+	return conf.writeSign(context.Background(), w, conf.prefixes()[0], data, time.Time{})
+}
 
-	msg := data
-	if len(conf.Prefixes) > 0 {
-		msg = conf.Prefixes[0] + msg
+func (conf *Configuration) signWithPrefix(prefix, data string) (string, error) {
+	return conf.signAt(context.Background(), prefix, data, time.Time{})
+}
+
+// signAt signs data with the given signing time if Timestamp is set;
+// zero means now.
+func (conf *Configuration) signAt(ctx context.Context, prefix, data string, issued time.Time) (string, error) {
+	var buf strings.Builder
+	buf.Grow(len(prefix) + len(data) + 80)
+	if _, err := conf.writeSign(ctx, &buf, prefix, data, issued); err != nil {
+		return "", err
 	}
+	return buf.String(), nil
+}
 
-	auth := hmacSHA256([]byte(msg), conf.Keys[0])
-	return msg + conf.sep() + auth
+// writeSign computes the signature, then writes the signed string to w.
+// Nothing is written if signing fails.
+func (conf *Configuration) writeSign(ctx context.Context, w io.Writer, prefix, data string, issued time.Time) (int, error) {
+	if sub := conf.forPrefix(prefix); sub != conf {
+		return sub.writeSign(ctx, w, prefix, data, issued)
+	}
+	if conf.IsVerifyOnly() {
+		return 0, errors.New("signedstrings: cannot sign with a verify-only configuration")
+	} else if isPrefixPattern(prefix) {
+		return 0, errors.New("signedstrings: cannot sign with prefix pattern " + prefix + ", use SignWithPrefix")
+	}
+
+	if conf.Normalize != nil {
+		data = conf.Normalize(data)
+	}
+	if conf.ASCIIOnly && !(isPrintableASCII(prefix) && isPrintableASCII(data)) {
+		return 0, errors.New("signedstrings: data contains non-ASCII or control characters")
+	} else if conf.RejectEmpty && data == "" {
+		return 0, errors.New("signedstrings: empty data")
+	} else if conf.QR && !isQRAlphanumeric(data) {
+		return 0, errors.New("signedstrings: data contains characters outside of the QR alphanumeric set")
+	}
+
+	// fields go between the separator and the MAC, covered by the signature
+	alg := conf.algorithms()[0]
+	if len(conf.KeyExpiry) > 0 {
+		id := conf.keyID(alg, 0)
+		if expiry, ok := conf.KeyExpiry[id]; ok {
+			if now := conf.now(); !now.Before(expiry) {
+				return 0, fmt.Errorf("signedstrings: signing key %s expired at %s", id, expiry.UTC().Format(time.RFC3339))
+			} else if conf.KeyExpiring != nil && expiry.Sub(now) <= conf.KeyExpiryWarning {
+				conf.KeyExpiring(id, expiry)
+			}
+		}
+	}
+	var fields string
+	if v := conf.versions()[0]; v != 1 {
+		fields += versionMark + strconv.Itoa(v) + tagSep
+	}
+	if alg != DefaultAlgorithm && !conf.FixedLength {
+		fields += alg.Name + tagSep
+	}
+	if conf.Timestamp {
+		if issued.IsZero() {
+			issued = conf.now()
+		}
+		fields += timestampMark + strconv.FormatInt(issued.Unix(), 10) + tagSep
+	}
+	if conf.Salt {
+		salt := make([]byte, saltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return 0, err
+		}
+		fields += saltMark + hex.EncodeToString(salt) + tagSep
+	}
+	covered := []string{conf.envTag(), prefix, data}
+	if fields != "" {
+		covered = append(covered, conf.sep(), fields, coveredEnd(fields))
+	} else if strings.Contains(prefix+data, "\x00") {
+		return 0, errors.New("signedstrings: data contains a NUL byte") // see coveredEnd
+	}
+	mac, err := macParts(ctx, conf.keys()[0], alg, covered)
+	if err != nil {
+		return 0, err
+	}
+	encoded := conf.macEncoding().encode(mac)
+
+	parts := []string{prefix, data, conf.sep(), fields, encoded}
+	if conf.SigFirst {
+		parts = []string{fields, encoded, conf.sep(), prefix, data}
+	}
+	var n int
+	for _, part := range parts {
+		m, err := io.WriteString(w, part)
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
 }
 
 // Validate verifies the signature on the given string, and returns the original
-// value if the signature is valid.
+// value if the signature is valid. Strings carrying caveats (see Attenuate)
+// are rejected, use ValidateCaveats to accept them.
 func (conf *Configuration) Validate(signed string) (string, error) {
+	return conf.validate(context.Background(), signed, nil)
+}
+
+// ValidateContext is like Validate, but passes ctx to external keys
+// implementing ContextExternalKey.
+func (conf *Configuration) ValidateContext(ctx context.Context, signed string) (string, error) {
+	return conf.validate(ctx, signed, nil)
+}
+
+// ReadValidate reads a signed string of at most limit bytes from r, e.g.
+// a request body, and validates it. Surrounding whitespace is ignored.
+// Longer input is rejected as Invalid without being buffered.
+func (conf *Configuration) ReadValidate(r io.Reader, limit int) (string, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return "", err
+	} else if len(raw) > limit {
+		return "", fmt.Errorf("%w: longer than %d bytes", Invalid, limit)
+	}
+	return conf.Validate(strings.TrimSpace(string(raw)))
+}
+
+// Validation describes a successfully validated string.
+type Validation struct {
+	Data      string
+	Prefix    string // the matched prefix
+	Pattern   string // the entry of Prefixes that matched, differs from Prefix for patterns
+	Algorithm string
+	KeyID     string // see KeyID
+}
+
+// ValidateDetailed is like Validate, but also tells which prefix, algorithm
+// and key the string matched, e.g. to route or segment metrics by token type.
+func (conf *Configuration) ValidateDetailed(signed string) (Validation, error) {
+	return conf.ValidateDetailedContext(context.Background(), signed)
+}
+
+// ValidateDetailedContext is like ValidateDetailed, but passes ctx to
+// external keys implementing ContextExternalKey.
+func (conf *Configuration) ValidateDetailedContext(ctx context.Context, signed string) (Validation, error) {
+	v, err := conf.validateToken(ctx, signed, nil)
+	if err != nil {
+		return Validation{}, err
+	}
+	if v.data, err = conf.transform(signed, v.data); err != nil {
+		return Validation{}, err
+	}
+	return Validation{v.data, v.matched, v.prefix, v.alg.Name, v.keyID}, nil
+}
+
+func (conf *Configuration) validate(ctx context.Context, signed string, check func(caveat string) error) (string, error) {
+	v, err := conf.validateToken(ctx, signed, check)
+	if err != nil {
+		return "", err
+	}
+	return conf.transform(signed, v.data)
+}
+
+// transform applies Transforms to the validated data of signed.
+func (conf *Configuration) transform(signed, data string) (string, error) {
+	for _, f := range conf.Transforms {
+		var err error
+		if data, err = f(data); err != nil {
+			if conf.RedactErrors {
+				return "", redact(signed, err)
+			}
+			return "", err
+		}
+	}
+	return data, nil
+}
+
+// validated describes a successfully validated string.
+type validated struct {
+	data     string
+	prefix   string // the entry of Prefixes
+	matched  string // the concrete prefix, differs from prefix for patterns
+	version  int
+	alg      *Algorithm
+	enc      *macEncoding
+	keyIndex int
+	keyID    string
+	issued   time.Time // zero if the string carries no timestamp
+	salted   bool
+}
+
+func (conf *Configuration) validateToken(ctx context.Context, signed string, check func(caveat string) error) (validated, error) {
+	v, err := conf.verifyToken(ctx, signed, check)
+	if err != nil && conf.ValidationFailed != nil {
+		conf.ValidationFailed(signed, err)
+	}
+	if err != nil && conf.RedactErrors && Reason(err) != "error" {
+		err = redact(signed, err)
+	}
+	return v, err
+}
+
+func (conf *Configuration) verifyToken(ctx context.Context, signed string, check func(caveat string) error) (validated, error) {
 	conf.sanityCheck()
+	if conf.Normalize != nil {
+		signed = conf.Normalize(signed)
+	}
+	if conf.ASCIIOnly && !isPrintableASCII(signed) {
+		return validated{}, Invalid
+	}
+
+	tok, ok := conf.parse(signed)
+	if !ok {
+		return validated{}, Invalid
+	}
+	matched, data, idx := conf.cutPrefix(tok.msg)
+	if idx < 0 {
+		return validated{}, Invalid
+	}
+	if sub := conf.forPrefix(conf.prefixes()[idx]); sub != conf {
+		return sub.verifyToken(ctx, signed, check) // reported and redacted by conf
+	}
+
+	if !containsInt(conf.versions(), tok.version) {
+		return validated{}, InvalidVersion
+	}
+	if conf.isLegacySHA1(tok) {
+		tok.alg, tok.covered = legacySHA1, tok.legacy
+	} else if !conf.accepts(tok.alg) {
+		return validated{}, InvalidAlg
+	}
+	encs := conf.wellFormedEncodings(tok)
+	if len(encs) == 0 {
+		return validated{}, MalformedSig
+	}
+
+	keyIndex, keyID, enc, err := conf.verify(ctx, tok, encs)
+	if err != nil {
+		return validated{}, err
+	} else if keyIndex < 0 {
+		return validated{}, InvalidSig
+	}
+	if len(conf.AcceptUntil) > 0 {
+		if deadline, ok := conf.AcceptUntil[keyID]; ok && conf.now().After(deadline) {
+			return validated{}, RetiredKey
+		}
+	}
+	if conf.RejectExpiredKeys && len(conf.KeyExpiry) > 0 {
+		if expiry, ok := conf.KeyExpiry[keyID]; ok && !conf.now().Before(expiry) {
+			return validated{}, ExpiredKey
+		}
+	}
+	if conf.RejectEmpty && data == "" {
+		return validated{}, fmt.Errorf("%w: empty data", Invalid)
+	}
+
+	if conf.MaxAge > 0 {
+		if tok.issued.IsZero() {
+			return validated{}, fmt.Errorf("%w: no timestamp", Invalid)
+		} else if conf.now().Sub(tok.issued) > conf.MaxAge {
+			return validated{}, &ExpiredError{data, Expired}
+		}
+	}
+
+	if err := checkCaveats(tok.caveats, check); err != nil {
+		if errors.Is(err, Expired) {
+			return validated{}, &ExpiredError{data, err}
+		}
+		return validated{}, err
+	}
+	if conf.CheckData != nil {
+		if err := conf.CheckData(data); err != nil {
+			return validated{}, fmt.Errorf("%w: %w", Invalid, err)
+		}
+	}
+	if conf.Revocations != nil {
+		if err := checkRevoked(ctx, conf.Revocations, tok, enc); err != nil {
+			return validated{}, err
+		}
+	}
+	if conf.KeyUsed != nil {
+		conf.KeyUsed(keyID, keyIndex)
+	}
+	v := validated{data, conf.prefixes()[idx], matched, tok.version, tok.alg, enc, keyIndex, keyID, tok.issued, tok.salt != ""}
+	if tok.alg == legacySHA1 {
+		return v, NeedsReissue // see Transcode
+	}
+	return v, nil
+}
+
+// token is a signed string split into its parts.
+type token struct {
+	msg     string     // prefix and data
+	version int        // see Configuration.Versions
+	alg     *Algorithm // nil if the tag is not a registered algorithm
+	covered string     // the signed message, see coveredEnd
+	fields  string     // the fields covered by the signature
+	legacy  string     // the part covered by legacy signatures
+	issued  time.Time  // zero if the string carries no timestamp
+	salt    string     // hex-encoded, see Configuration.Salt
+	caveats []string   // encoded caveats, see Attenuate
+	mac     string     // encoded signature
+}
+
+// parse splits a signed string into its parts.
+func (conf *Configuration) parse(signed string) (token, bool) {
+	if seps := conf.seps(); len(seps) > 1 {
+		// the data may contain another separator, so pick the first one
+		// yielding a well-formed signature
+		for _, sep := range seps {
+			tok, ok := parseSep(signed, sep, conf.SigFirst)
+			if ok && tok.alg != nil && (conf.isLegacySHA1(tok) || len(conf.wellFormedEncodings(tok)) > 0) {
+				return tok, true
+			}
+		}
+		return token{}, false
+	} else if !conf.FixedLength {
+		return parseSep(signed, conf.sep(), conf.SigFirst)
+	}
+	n := conf.fixedMACLen()
+	if len(signed) < n {
+		return token{}, false
+	}
+	msg := signed[:len(signed)-n]
+	if strings.Contains(msg, "\x00") {
+		return token{}, false // see coveredEnd
+	}
+	return token{msg: msg, version: 1, alg: conf.algorithms()[0], covered: msg, legacy: msg, mac: signed[len(msg):]}, true
+}
+
+// fixedMACLen is the length of encoded signatures, see FixedLength.
+func (conf *Configuration) fixedMACLen() int {
+	return conf.macEncoding().encodedLen(conf.algorithms()[0].Hash().Size())
+}
+
+// cutSig splits a signed string into the signature with its fields and
+// the rest, see joinSig.
+func (conf *Configuration) cutSig(signed string) (head, auth string) {
+	if conf.SigFirst {
+		auth, head, _ = strings.Cut(signed, conf.sep())
+		return
+	} else if conf.FixedLength {
+		n := len(signed) - conf.fixedMACLen()
+		return signed[:n], signed[n:]
+	}
+	head, auth, _ = cutLast(signed, conf.sep())
+	return
+}
+
+// joinSig is the inverse of cutSig.
+func (conf *Configuration) joinSig(head, auth string) string {
+	if conf.SigFirst {
+		return auth + conf.sep() + head
+	}
+	return head + conf.sep() + auth
+}
 
-	msg, auth, ok := cutLast(signed, conf.sep())
+// containsSep returns whether s contains the separator, and so cannot be
+// the part of a signed string after it.
+func (conf *Configuration) containsSep(s string) bool {
+	return !conf.FixedLength && strings.Contains(s, conf.sep())
+}
+
+func parseSep(signed, sep string, sigFirst bool) (token, bool) {
+	if sigFirst {
+		// rearrange into the default layout, which the signature covers
+		auth, msg, ok := strings.Cut(signed, sep)
+		if !ok {
+			return token{}, false
+		}
+		return parseToken(msg+sep+auth, sep)
+	}
+	return parseToken(signed, sep)
+}
+
+func parseToken(signed, sep string) (token, bool) {
+	msg, auth, ok := cutLast(signed, sep)
 	if !ok || len(auth) == 0 {
-		return "", Invalid
+		return token{}, false
 	}
+	tok := token{msg: msg, version: 1, alg: DefaultAlgorithm, covered: msg, legacy: msg}
 
-	data, idx := cutLongestPrefix(msg, conf.prefixes())
-	if idx < 0 {
-		return "", Invalid
+	fields := strings.Split(auth, tagSep)
+	tok.mac, fields = fields[len(fields)-1], fields[:len(fields)-1]
+	if len(tok.mac) == 0 {
+		return token{}, false
 	}
+	// fields in front of caveats are covered by the signature
+	start := len(msg) + len(sep)
+	n := start
+	next := func(mark string) (string, bool) {
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], mark) {
+			return "", false
+		}
+		f := fields[0]
+		n += len(f) + len(tagSep)
+		tok.fields = signed[start:n]
+		tok.covered = signed[:n] + coveredEnd(tok.fields)
+		tok.legacy = signed[:n]
+		fields = fields[1:]
+		return f[len(mark):], true
+	}
+	if v, found := next(versionMark); found {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 2 || strconv.Itoa(version) != v {
+			return token{}, false
+		}
+		tok.version = version
+	}
+	if len(fields) > 0 && isAlgorithmField(fields[0]) {
+		tag, _ := next("")
+		tok.alg = LookupAlgorithm(tag)
+		if tok.alg == DefaultAlgorithm {
+			return token{}, false // never tagged, see coveredEnd
+		}
+	}
+	if v, found := next(timestampMark); found {
+		unix, ok := parseTimestamp(v)
+		if !ok {
+			return token{}, false
+		}
+		tok.issued = time.Unix(unix, 0)
+	}
+	if v, found := next(saltMark); found {
+		if _, err := hex.DecodeString(v); err != nil || v == "" {
+			return token{}, false
+		}
+		tok.salt = v
+	}
+	for _, f := range fields {
+		if !isCaveatField(f) {
+			return token{}, false
+		}
+	}
+	if tok.fields == "" && strings.Contains(msg, "\x00") {
+		return token{}, false // see coveredEnd
+	}
+	tok.caveats = fields
+	return tok, true
+}
+
+// coveredEnd terminates the message covered by the signature of a string
+// with fields, which is the prefix, data, separator and fields, with a NUL
+// byte and the length of the fields. Strings without fields sign just the
+// prefix and data, as in the original format, but cannot contain NUL bytes.
+// The message of every string is thus unambiguous: data like "foo-HS256." or
+// "bob-@9999999999." cannot pass for data with an algorithm tag or timestamp
+// once the separator after it is removed.
+func coveredEnd(fields string) string {
+	return "\x00" + strconv.Itoa(len(fields))
+}
 
-	keyIndex := -1
-	for i, key := range conf.Keys {
-		expected := hmacSHA256([]byte(msg), key)
-		if subtle.ConstantTimeCompare([]byte(auth), []byte(expected)) == 1 {
-			keyIndex = i
-			break
+// TokensEqual compares two tokens in constant time, for callers who store
+// issued tokens and look them up; comparing with == leaks how long the common
+// prefix is. The tokens are hashed first, so their lengths do not leak either.
+func TokensEqual(a, b string) bool {
+	ha, hb := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// IsVerifyOnly returns whether the configuration only holds public keys.
+func (conf *Configuration) IsVerifyOnly() bool {
+	return len(conf.ActiveKeys()) == 0 && len(conf.ExternalKeys) == 0 && len(conf.PublicKeys) > 0
+}
+
+// verify returns the index and ID of the key that produced the signature and
+// the encoding it matched in, one of encs, or -1. Both come from the same
+// snapshot of the keys, so they agree even if SetKeys runs concurrently.
+func (conf *Configuration) verify(ctx context.Context, tok token, encs []*macEncoding) (int, string, *macEncoding, error) {
+	alg := tok.alg
+	covered := []byte(conf.envTag() + tok.covered)
+	if alg.IsAsymmetric() {
+		if len(tok.caveats) > 0 {
+			return -1, "", nil, nil
+		}
+		pubs, err := conf.publicKeys(alg)
+		if err != nil {
+			return -1, "", nil, err
+		}
+		for _, enc := range encs {
+			sig, err := enc.decode(tok.mac)
+			if err != nil {
+				continue
+			}
+			for i, pub := range pubs {
+				if alg.Verify(pub, covered, sig) {
+					return i, KeyID(pub), enc, nil
+				}
+			}
 		}
+		return -1, "", nil, nil
 	}
-	if keyIndex < 0 {
-		return "", InvalidSig
+
+	keys, raw := conf.keySnapshot()
+	for i, key := range keys {
+		mac, err := keyMAC(ctx, key, alg, covered)
+		if err != nil {
+			return -1, "", nil, err
+		}
+		for _, c := range tok.caveats {
+			mac = alg.sum([]byte(c), mac)
+		}
+		for _, enc := range encs {
+			if subtle.ConstantTimeCompare([]byte(tok.mac), []byte(enc.encode(mac))) == 1 {
+				return i, conf.hmacKeyID(raw, i), enc, nil
+			}
+		}
 	}
+	return -1, "", nil, nil
+}
 
-	return data, nil
+func (conf *Configuration) publicKeys(alg *Algorithm) ([][]byte, error) {
+	keys := conf.ActiveKeys()
+	pubs := make([][]byte, 0, len(keys)+len(conf.PublicKeys))
+	for _, key := range keys {
+		pub, err := alg.Public(key)
+		if err != nil {
+			return nil, err
+		}
+		pubs = append(pubs, pub)
+	}
+	return append(pubs, conf.PublicKeys...), nil
 }
 
-func (conf *Configuration) sanityCheck() {
-	if len(conf.Keys) == 0 {
-		panic("signedstrings: not configured")
+// Check verifies that the configuration is usable, returning the same error
+// Sign and Validate would panic with. Call it at startup to catch
+// misconfiguration early.
+func (conf *Configuration) Check() error {
+	if conf.state != nil {
+		return nil // checked by Compile and SetKeys
+	}
+	if len(conf.Keys) == 0 && len(conf.ExternalKeys) == 0 && len(conf.PublicKeys) == 0 && !conf.allPrefixesKeyed() {
+		return errors.New("signedstrings: not configured")
+	} else if len(conf.Keys) > 0 && len(conf.ExternalKeys) > 0 {
+		return errors.New("signedstrings: both Keys and ExternalKeys are set")
 	}
 	for _, key := range conf.Keys {
 		if len(key) == 0 {
-			panic("signedstrings: empty key")
+			return errors.New("signedstrings: empty key")
 		} else if len(key) < MinKeyLen {
-			panic("signedstrings: short key")
+			return errors.New("signedstrings: short key")
+		}
+	}
+	for _, key := range conf.PublicKeys {
+		if len(key) == 0 {
+			return errors.New("signedstrings: empty key")
+		}
+	}
+	for _, prefix := range conf.prefixes() {
+		if isPrefixPattern(prefix) && (strings.HasSuffix(prefix, "*") || strings.Contains(prefix, "**")) {
+			return errors.New("signedstrings: prefix pattern " + prefix + " must have text after each *")
 		}
 	}
+	for prefix := range conf.PrefixKeys {
+		if !containsString(conf.prefixes(), prefix) {
+			return errors.New("signedstrings: PrefixKeys has unknown prefix " + prefix)
+		} else if len(conf.PrefixPolicies[prefix].Keys) > 0 {
+			return errors.New("signedstrings: prefix " + prefix + " has keys in both PrefixKeys and PrefixPolicies")
+		}
+	}
+	for prefix := range conf.PrefixPolicies {
+		if !containsString(conf.prefixes(), prefix) {
+			return errors.New("signedstrings: PrefixPolicies has unknown prefix " + prefix)
+		}
+	}
+	for _, prefix := range conf.prefixes() {
+		if conf.hasPrefixConf(prefix) {
+			if err := conf.prefixConf(prefix).Check(); err != nil {
+				return err
+			}
+		}
+	}
+	algs := conf.algorithms()
+	for i, alg := range algs {
+		if alg == nil {
+			return errors.New("signedstrings: unknown algorithm " + conf.Algorithms[i])
+		} else if conf.FIPS && !alg.FIPS {
+			return errors.New("signedstrings: algorithm " + alg.Name + " is not FIPS-approved")
+		} else if conf.FIPS && customHasher.Load() && !alg.IsAsymmetric() {
+			return errors.New("signedstrings: FIPS mode cannot use a custom Hasher")
+		} else if conf.IsVerifyOnly() && !alg.IsAsymmetric() {
+			return errors.New("signedstrings: algorithm " + alg.Name + " cannot be used with public keys only")
+		}
+	}
+	if conf.Sep != "" && len(conf.Seps) > 0 {
+		return errors.New("signedstrings: Sep cannot be combined with Seps")
+	}
+	for _, sep := range conf.Seps {
+		if sep == "" {
+			return errors.New("signedstrings: Seps contains an empty separator")
+		}
+	}
+	for _, sep := range conf.seps() {
+		if (len(algs) > 1 || algs[0] != DefaultAlgorithm) && strings.Contains(sep, tagSep) {
+			return errors.New("signedstrings: separator conflicts with algorithm tags")
+		}
+		if (conf.Timestamp || conf.MaxAge > 0) && strings.ContainsAny(sep, tagSep+timestampMark) {
+			return errors.New("signedstrings: separator conflicts with timestamps")
+		}
+		if conf.Salt && strings.ContainsAny(sep, tagSep+saltMark) {
+			return errors.New("signedstrings: separator conflicts with salt")
+		}
+		for _, v := range conf.versions() {
+			if v > 1 && strings.ContainsAny(sep, tagSep+versionMark) {
+				return errors.New("signedstrings: separator conflicts with version markers")
+			}
+		}
+		for _, enc := range conf.macEncodings() {
+			if strings.ContainsAny(sep, enc.alphabet) {
+				return errors.New("signedstrings: separator conflicts with " + enc.name + " signatures")
+			}
+		}
+	}
+	for _, v := range conf.versions() {
+		if v < 1 || v > maxVersion {
+			return fmt.Errorf("signedstrings: unsupported format version %d", v)
+		}
+	}
+	if strings.Contains(conf.Environment, "\x00") {
+		return errors.New("signedstrings: Environment contains a NUL byte")
+	}
+	for _, name := range conf.SigEncodings {
+		if lookupMACEncoding(name) == nil {
+			return errors.New("signedstrings: unknown signature encoding " + name)
+		}
+	}
+	if conf.FixedLength {
+		if conf.Sep != "" || len(conf.Seps) > 0 {
+			return errors.New("signedstrings: FixedLength cannot be combined with Sep")
+		} else if len(algs) != 1 || algs[0].IsAsymmetric() {
+			return errors.New("signedstrings: FixedLength requires a single HMAC algorithm")
+		} else if len(conf.macEncodings()) != 1 {
+			return errors.New("signedstrings: FixedLength requires a single signature encoding")
+		} else if conf.Timestamp || conf.Salt || conf.MaxAge > 0 || !conf.LegacySHA1Until.IsZero() || len(conf.versions()) != 1 || conf.versions()[0] != 1 {
+			return errors.New("signedstrings: FixedLength does not support timestamps, salt, legacy signatures and version markers")
+		}
+	}
+	if conf.SigFirst && conf.FixedLength {
+		return errors.New("signedstrings: SigFirst cannot be combined with FixedLength")
+	}
+	if conf.QR {
+		if len(conf.SigEncodings) > 0 {
+			return errors.New("signedstrings: QR mode cannot be combined with SigEncodings")
+		} else if conf.Timestamp || conf.Salt || conf.versions()[0] != 1 {
+			return errors.New("signedstrings: QR mode does not support timestamps, salt and version markers")
+		} else if !isQRAlphanumeric(conf.sep() + strings.Join(conf.prefixes(), "")) {
+			return errors.New("signedstrings: separator and prefixes must be in the QR alphanumeric set")
+		}
+	}
+	return nil
+}
+
+func (conf *Configuration) sanityCheck() {
+	if conf.state != nil {
+		return
+	}
+	if err := conf.Check(); err != nil {
+		panic(err)
+	}
 }
 
 func (conf *Configuration) sep() string {
+	if st := conf.compiled(); st != nil {
+		return st.sep
+	}
 	if s := conf.Sep; len(s) > 0 {
 		return s
+	} else if len(conf.Seps) > 0 {
+		return conf.Seps[0]
+	} else if conf.FixedLength {
+		return ""
 	}
 	return "-"
 }
 
+func (conf *Configuration) seps() []string {
+	if len(conf.Seps) > 0 {
+		return conf.Seps
+	}
+	return []string{conf.sep()}
+}
+
+func (conf *Configuration) versions() []int {
+	if len(conf.Versions) == 0 {
+		return defaultVersions
+	}
+	return conf.Versions
+}
+
+// envTag is prepended to the signed part of strings, see Environment.
+// The NUL byte keeps it from running into the prefix.
+func (conf *Configuration) envTag() string {
+	if conf.Environment == "" {
+		return ""
+	}
+	return "signedstrings env " + conf.Environment + "\x00"
+}
+
+func (conf *Configuration) algorithms() []*Algorithm {
+	if st := conf.compiled(); st != nil {
+		return st.algs
+	}
+	if len(conf.Algorithms) == 0 {
+		return []*Algorithm{DefaultAlgorithm}
+	}
+	algs := make([]*Algorithm, len(conf.Algorithms))
+	for i, name := range conf.Algorithms {
+		algs[i] = LookupAlgorithm(name)
+	}
+	return algs
+}
+
+func (conf *Configuration) accepts(alg *Algorithm) bool {
+	if alg == nil {
+		return false
+	}
+	for _, a := range conf.algorithms() {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
 func (conf *Configuration) prefixes() []string {
+	if st := conf.compiled(); st != nil {
+		return st.prefixes
+	}
 	if v := conf.Prefixes; len(v) > 0 {
 		return v
 	}
@@ -154,26 +1071,128 @@ func isWhitespaceOrComma(r rune) bool {
 	return r == ' ' || r == ','
 }
 
-func hmacSHA256(message, key []byte) string {
-	var hash [sha256.Size]byte
-	alg := hmac.New(sha256.New, key)
-	alg.Write(message)
-	alg.Sum(hash[:0])
-	return hex.EncodeToString(hash[:])
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7E {
+			return false
+		}
+	}
+	return true
 }
 
 var emptyPrefixes = []string{""}
 
+var defaultVersions = []int{1}
+
+// maxVersion is the latest wire format version. Version 2 only differs from
+// version 1 by carrying a marker.
+const maxVersion = 2
+
+// versionMark starts the version field, see Configuration.Versions.
+const versionMark = "!"
+
+// tagSep separates the algorithm tag from the signature.
+const tagSep = "."
+
+// timestampMark starts the timestamp field, see Configuration.Timestamp.
+const timestampMark = "@"
+
+// saltMark starts the salt field, see Configuration.Salt.
+const saltMark = "_"
+
+// saltLen is the number of random bytes in a salt.
+const saltLen = 16
+
+func isAlgorithmField(field string) bool {
+	return !isCaveatField(field) && !strings.HasPrefix(field, timestampMark) && !strings.HasPrefix(field, saltMark) && !strings.HasPrefix(field, versionMark)
+}
+
+func parseTimestamp(s string) (int64, bool) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	unix, err := strconv.ParseInt(s, 10, 64)
+	return unix, err == nil
+}
+
+// cutPrefix finds the longest prefix or prefix pattern that msg starts with,
+// and returns the matched prefix, the rest of msg, and the index of the entry
+// of Prefixes, or -1.
+func (conf *Configuration) cutPrefix(msg string) (prefix, data string, index int) {
+	prefixes := conf.prefixes()
+	data, index = cutLongestPrefix(msg, prefixes)
+	if index >= 0 {
+		prefix = msg[:len(msg)-len(data)]
+	}
+	for i, p := range prefixes {
+		if !isPrefixPattern(p) {
+			continue
+		}
+		if n := matchPrefixPattern(msg, p); n > len(prefix) || n >= 0 && index < 0 {
+			prefix, data, index = msg[:n], msg[n:], i
+		}
+	}
+	return prefix, data, index
+}
+
+// prefixEntry returns the entry of Prefixes that is or matches prefix.
+func (conf *Configuration) prefixEntry(prefix string) (string, bool) {
+	for _, p := range conf.prefixes() {
+		if p == prefix || isPrefixPattern(p) && matchPrefixPattern(prefix, p) == len(prefix) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func isPrefixPattern(prefix string) bool {
+	return strings.Contains(prefix, "*")
+}
+
+// matchPrefixPattern returns the length of the prefix of s matching pattern,
+// or -1. Each * matches one or more bytes up to the next occurrence of the
+// byte following it. Takes variable time, see cutLongestPrefix.
+func matchPrefixPattern(s, pattern string) int {
+	lits := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, lits[0]) {
+		return -1
+	}
+	n := len(lits[0])
+	for _, lit := range lits[1:] {
+		i := strings.IndexByte(s[n:], lit[0])
+		if i <= 0 || !strings.HasPrefix(s[n+i:], lit) {
+			return -1
+		}
+		n += i + len(lit)
+	}
+	return n
+}
+
+// cutLongestPrefix finds the longest of prefixes that str starts with, and
+// returns its index and the rest of str, or -1. Every prefix is compared in
+// constant time, so timing reveals neither which prefix matched nor whether
+// any did, only the lengths of str and the prefixes.
+//
+// Prefix patterns are the exception: cutPrefix matches them afterwards with
+// matchPrefixPattern, which takes variable time, so with patterns configured
+// timing may reveal which pattern matched and how long the metadata is.
+// Patterns carry non-secret metadata such as tenants or regions, and the
+// signature check that follows is constant-time either way.
 func cutLongestPrefix(str string, prefixes []string) (after string, index int) {
-	index = -1
+	index, n := -1, -1
 	for i, p := range prefixes {
-		if a, found := strings.CutPrefix(str, p); found {
-			if index < 0 || len(p) > len(prefixes[index]) {
-				after, index = a, i
-			}
+		var match int
+		if len(p) <= len(str) {
+			match = subtle.ConstantTimeCompare([]byte(str[:len(p)]), []byte(p))
 		}
+		match &= subtle.ConstantTimeLessOrEq(n+1, len(p))
+		index = subtle.ConstantTimeSelect(match, i, index)
+		n = subtle.ConstantTimeSelect(match, len(p), n)
 	}
-	return
+	if index < 0 {
+		return "", -1
+	}
+	return str[n:], index
 }
 
 // cutLast slices s around the last instance of sep, returning the text before and after sep.
@@ -2,9 +2,14 @@ package signedstrings_test
 
 import (
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/andreyvit/signedstrings"
 )
@@ -82,6 +87,29 @@ func ExampleKeys() {
 	// Output: d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2 65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c
 }
 
+func Example_acceptUntil() {
+	oldKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	old := signedstrings.Configuration{Keys: [][]byte{oldKey}}
+	signed := old.Sign("foo")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey, oldKey},
+		AcceptUntil: map[string]time.Time{
+			signedstrings.KeyID(oldKey): time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Now: func() time.Time { return now },
+	}
+	print(conf.Validate(signed))
+	now = now.AddDate(0, 2, 0)
+	print(conf.Validate(signed))
+	print(conf.Validate(conf.Sign("foo")))
+
+	// Output: foo
+	// err: invalid signature: key retired
+	// foo
+}
+
 func TestSanityCheck_noKeys(t *testing.T) {
 	conf := signedstrings.Configuration{}
 	assertPanic(t, "signedstrings: not configured", func() {
@@ -138,3 +166,494 @@ func must[T any](v T, err error) T {
 	}
 	return v
 }
+
+func ExampleConfiguration_ValidateDetailed() {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Prefixes:   []string{"TOKEN-", "OLD-"},
+		Algorithms: []string{"HS256", "HS512"},
+	}
+	fmt.Println(conf.ValidateDetailed("TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"))
+	print(conf.ValidateDetailed("OLD-foo-1111111111111111111111111111111111111111111111111111111111111111"))
+
+	// Output: {foo TOKEN- TOKEN- HS256 a814acf20ffba3c8} <nil>
+	// err: invalid signature
+}
+
+func Example_normalize() {
+	// use norm.NFC.String from golang.org/x/text/unicode/norm in real code
+	nfc := strings.NewReplacer("e\u0301", "\u00e9").Replace
+
+	conf := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Normalize: nfc,
+	}
+	composed := conf.Sign("caf\u00e9.txt")
+	decomposed := conf.Sign("cafe\u0301.txt")
+	fmt.Println(composed == decomposed)
+
+	data := must(conf.Validate(strings.Replace(composed, "\u00e9", "e\u0301", 1)))
+	fmt.Println(data == "caf\u00e9.txt")
+
+	// Output: true
+	// true
+}
+
+func Example_asciiOnly() {
+	conf := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		ASCIIOnly: true,
+	}
+	print(conf.TrySign("foo"))
+	print(conf.TrySign("café"))
+	print(conf.TrySign("foo\nbar"))
+	print(conf.Validate("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+	print(conf.Validate("foo\x00-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+
+	// Output: foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334
+	// err: signedstrings: data contains non-ASCII or control characters
+	// err: signedstrings: data contains non-ASCII or control characters
+	// foo
+	// err: invalid string
+}
+
+func Example_rejectEmpty() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	empty := conf.Sign("")
+	fmt.Println(empty)
+	print(conf.Validate(empty))
+
+	conf.RejectEmpty = true
+	print(conf.TrySign(""))
+	print(conf.Validate(empty))
+
+	// Output: -b140d49385956359cca4df0830efefcbbb1d6770b00721221f23fe4cf3d6b5f0
+	//
+	// err: signedstrings: empty data
+	// err: invalid string: empty data
+}
+
+func Example_checkData() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		CheckData: func(data string) error {
+			if _, err := strconv.ParseUint(data, 10, 64); err != nil {
+				return errors.New("not a user ID")
+			}
+			return nil
+		},
+	}
+	print(conf.Validate(conf.Sign("42")))
+	_, err := conf.Validate(conf.Sign("admin"))
+	fmt.Println(err, errors.Is(err, signedstrings.Invalid))
+
+	// Output: 42
+	// invalid string: not a user ID true
+}
+
+func Example_transforms() {
+	legacyIDs := map[string]string{"u1": "42"}
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Transforms: []func(string) (string, error){
+			func(data string) (string, error) {
+				raw, err := hex.DecodeString(data)
+				return string(raw), err
+			},
+			func(data string) (string, error) {
+				if id, ok := legacyIDs[data]; ok {
+					return id, nil
+				}
+				return data, nil
+			},
+		},
+	}
+	print(conf.Validate(conf.Sign(hex.EncodeToString([]byte("u1")))))
+	print(conf.Validate(conf.Sign(hex.EncodeToString([]byte("43")))))
+	print(conf.Validate(conf.Sign("zz")))
+
+	// Output: 42
+	// 43
+	// err: encoding/hex: invalid byte: U+007A 'z'
+}
+
+func Example_redactErrors() {
+	conf := signedstrings.Configuration{
+		Keys:         [][]byte{exampleKey},
+		RedactErrors: true,
+		CheckData: func(data string) error {
+			return fmt.Errorf("bad user ID %q", data)
+		},
+	}
+	_, err := conf.Validate(conf.Sign("alice@example.com"))
+	fmt.Println(err, errors.Is(err, signedstrings.Invalid))
+	_, err = conf.ValidateCaveats(must(conf.Attenuate(conf.Sign("foo"), "ip=10.0.0.1")), nil)
+	fmt.Println(err, errors.Is(err, signedstrings.UnsatisfiedCaveat))
+
+	// Output: invalid string (fingerprint 93b8e5b4be84900d) true
+	// unsatisfied caveat (fingerprint 1851434801424dfd) true
+}
+
+func Example_keyExpiry() {
+	now := time.Unix(1700000000, 0)
+	conf := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		KeyExpiry: map[string]time.Time{signedstrings.KeyID(exampleKey): now.Add(time.Hour)},
+		Now:       func() time.Time { return now },
+	}
+	signed := conf.Sign("foo")
+
+	now = now.Add(2 * time.Hour)
+	print(conf.TrySign("foo"))
+	print(conf.Validate(signed))
+	conf.RejectExpiredKeys = true
+	print(conf.Validate(signed))
+
+	// Output: err: signedstrings: signing key a814acf20ffba3c8 expired at 2023-11-14T23:13:20Z
+	// foo
+	// err: invalid signature: key expired
+}
+
+func Example_keyExpiring() {
+	now := time.Unix(1700000000, 0)
+	conf := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		KeyExpiry: map[string]time.Time{signedstrings.KeyID(exampleKey): now.Add(7 * 24 * time.Hour)},
+		KeyExpiring: func(keyID string, expires time.Time) {
+			fmt.Println("rotate", keyID, "before", expires.UTC().Format(time.RFC3339))
+		},
+		KeyExpiryWarning: 24 * time.Hour,
+		Now:              func() time.Time { return now },
+	}
+	conf.Sign("foo")
+	now = now.Add(6*24*time.Hour + time.Minute)
+	conf.Sign("foo")
+
+	// Output: rotate a814acf20ffba3c8 before 2023-11-21T22:13:20Z
+}
+
+func Example_malformedSig() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	print(conf.Validate("foo-d1d"))
+	print(conf.Validate("foo-x1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+	print(conf.Validate("foo-11d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+
+	_, err := conf.Validate("foo-d1d")
+	fmt.Println(errors.Is(err, signedstrings.InvalidSig))
+
+	// Output: err: invalid signature: malformed
+	// err: invalid signature: malformed
+	// err: invalid signature
+	// true
+}
+
+func TestTokensEqual(t *testing.T) {
+	const token = "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{token, token, true},
+		{"", "", true},
+		{token, token[:len(token)-1] + "5", false},
+		{token, token[:len(token)-1], false},
+		{token, "", false},
+	}
+	for _, tt := range tests {
+		if got := signedstrings.TokensEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("TokensEqual(%q, %q) = %v, wanted %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Example_maxAge() {
+	now := time.Unix(1700000000, 0)
+	issuer := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Timestamp: true,
+		Now:       func() time.Time { return now },
+	}
+	signed := issuer.Sign("foo")
+	fmt.Println(signed)
+
+	validator := signedstrings.Configuration{
+		Keys:   [][]byte{exampleKey},
+		MaxAge: time.Hour,
+		Now:    func() time.Time { return now.Add(time.Hour) },
+	}
+	print(validator.Validate(signed))
+	print(validator.Validate(strings.Replace(signed, "@1700000000", "@1700000001", 1)))
+	print(validator.Validate("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+
+	validator.MaxAge = time.Minute
+	print(validator.Validate(signed))
+
+	// Output: foo-@1700000000.5409dd7aa3c3b931f55bb92f154861f1abdfb28d47680102e216e9cef7cdeb22
+	// foo
+	// err: invalid signature
+	// err: invalid string: no timestamp
+	// err: expired
+}
+
+func TestTimestamp_tagsAndCaveats(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{exampleKey},
+		Algorithms: []string{"HS512"},
+		Timestamp:  true,
+		MaxAge:     time.Hour,
+	}
+	signed := must(conf.Attenuate(conf.Sign("foo"), "bar"))
+	if !strings.HasPrefix(signed, "foo-HS512.@") {
+		t.Errorf("Sign = %q", signed)
+	}
+	data, err := conf.ValidateCaveats(signed, func(caveat string) error { return nil })
+	if err != nil || data != "foo" {
+		t.Errorf("ValidateCaveats(%q) = %q, %v", signed, data, err)
+	}
+
+	conf.Sep = "@"
+	if err := conf.Check(); err == nil || err.Error() != "signedstrings: separator conflicts with timestamps" {
+		t.Errorf("Check() = %v", err)
+	}
+}
+
+func TestSign_nul(t *testing.T) {
+	plain := signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	tagged := signedstrings.Configuration{Keys: [][]byte{exampleKey}, Algorithms: []string{"HS512", "HS256"}}
+
+	// an untagged signature of this would pass for a tagged string
+	if _, err := plain.TrySign("foo-HS512.\x006"); err == nil {
+		t.Error("TrySign(NUL) succeeded")
+	}
+	signed := tagged.Sign("foo\x00bar")
+	if data, err := tagged.Validate(signed); err != nil || data != "foo\x00bar" {
+		t.Errorf("Validate(%q) = %q, %v", signed, data, err)
+	}
+}
+
+func Example_salt() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Salt: true,
+	}
+	a, b := conf.Sign("foo"), conf.Sign("foo")
+	fmt.Println(a[:5], len(a), a == b)
+	print(conf.Validate(a))
+	print(conf.Validate(b))
+	print(conf.Validate(a[:38] + b[38:])) // swapped salt
+
+	// Output: foo-_ 102 false
+	// foo
+	// foo
+	// err: invalid signature
+}
+
+func ExampleConfiguration_WriteSign() {
+	conf := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"TOKEN-"},
+	}
+	n, err := conf.WriteSign(os.Stdout, "foo")
+	fmt.Println()
+	fmt.Println(n, err)
+
+	// Output: TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39
+	// 74 <nil>
+}
+
+func ExampleConfiguration_ReadValidate() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	body := "foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334\n"
+	print(conf.ReadValidate(strings.NewReader(body), 100))
+	print(conf.ReadValidate(strings.NewReader(body), 60))
+	print(conf.ReadValidate(strings.NewReader(strings.Repeat("x", 1<<20)), 100))
+
+	// Output: foo
+	// err: invalid string: longer than 60 bytes
+	// err: invalid string: longer than 100 bytes
+}
+
+func Example_qr() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		QR:   true,
+	}
+	signed := conf.Sign("TICKET:42")
+	fmt.Println(signed)
+	print(conf.Validate(signed))
+	print(conf.TrySign("ticket:42"))
+	print(conf.Attenuate(signed, "x"))
+
+	// Output: TICKET:42-7WENQVALGUCEJUXSARD3EWKYMS3TYWFBIPHEQWBYKGULXUV5TUBA
+	// TICKET:42
+	// err: signedstrings: data contains characters outside of the QR alphanumeric set
+	// err: signedstrings: QR mode does not support caveats
+}
+
+func Example_environment() {
+	staging := signedstrings.Configuration{
+		Keys:        [][]byte{exampleKey},
+		Environment: "staging",
+	}
+	production := staging
+	production.Environment = "production"
+
+	signed := staging.Sign("foo")
+	fmt.Println(signed)
+	print(staging.Validate(signed))
+	print(production.Validate(signed))
+	print((&signedstrings.Configuration{Keys: [][]byte{exampleKey}}).Validate(signed))
+
+	// Output: foo-367ba21ba8c3fa3fa546236dcb4d94398532064865096317c2294b7620cf4471
+	// foo
+	// err: invalid signature
+	// err: invalid signature
+}
+
+func Example_versions() {
+	old := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	migrating := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Versions: []int{2, 1},
+	}
+	migrated := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Versions: []int{2},
+	}
+
+	v1, v2 := old.Sign("foo"), migrating.Sign("foo")
+	fmt.Println(v2)
+	print(migrating.Validate(v1))
+	print(migrated.Validate(v2))
+	print(migrated.Validate(v1))
+	print(old.Validate(v2))
+	print(old.Validate("foo-!02.d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+	fmt.Println((&signedstrings.Configuration{Keys: [][]byte{exampleKey}, Versions: []int{3}}).Check())
+
+	// Output: foo-!2.de59d0e7cb12a2a0547d0302639df6fb9730bbb8add91077fb8feb28f57e85db
+	// foo
+	// foo
+	// err: unacceptable format version
+	// err: unacceptable format version
+	// err: invalid string
+	// signedstrings: unsupported format version 3
+}
+
+func Example_sigEncodings() {
+	old := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Sep:  ":",
+	}
+	migrating := signedstrings.Configuration{
+		Keys:         [][]byte{exampleKey},
+		Sep:          ":",
+		SigEncodings: []string{"base64", "hex"},
+	}
+	migrated := signedstrings.Configuration{
+		Keys:         [][]byte{exampleKey},
+		Sep:          ":",
+		SigEncodings: []string{"base64"},
+	}
+
+	signed := migrating.Sign("foo")
+	fmt.Println(signed)
+	print(migrating.Validate(old.Sign("foo")))
+	print(migrated.Validate(signed))
+	print(migrated.Validate(old.Sign("foo")))
+	fmt.Println(migrating.Transcode(old.Sign("foo")))
+	fmt.Println((&signedstrings.Configuration{Keys: [][]byte{exampleKey}, SigEncodings: []string{"base64"}}).Check())
+
+	// Output: foo:0dhiA6ksm_-t7c11rWhXznhksVqwz7gm78tvNh4NAzQ
+	// foo
+	// foo
+	// err: invalid signature: malformed
+	// foo:0dhiA6ksm_-t7c11rWhXznhksVqwz7gm78tvNh4NAzQ <nil>
+	// signedstrings: separator conflicts with base64 signatures
+}
+
+func Example_overlappingPrefixes() {
+	conf := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"T-", "", "T-ADMIN-"},
+	}
+	for _, prefix := range conf.Prefixes {
+		v, err := conf.ValidateDetailed(must(conf.SignWithPrefix(prefix, "foo")))
+		fmt.Printf("%q %q %v\n", v.Prefix, v.Data, err)
+	}
+
+	// Output: "T-" "foo" <nil>
+	// "" "foo" <nil>
+	// "T-ADMIN-" "foo" <nil>
+}
+
+func Example_fixedLength() {
+	conf := signedstrings.Configuration{
+		Keys:        [][]byte{exampleKey},
+		FixedLength: true,
+	}
+	signed := conf.Sign("foo-bar")
+	fmt.Println(signed)
+	print(conf.Validate(signed))
+	print(conf.Validate("x" + signed))
+	print(conf.Validate("foo"))
+	print(conf.Attenuate(signed, "x"))
+	fmt.Println((&signedstrings.Configuration{Keys: [][]byte{exampleKey}, FixedLength: true, Timestamp: true}).Check())
+
+	// Output: foo-bar53d1af0daad4beee4b16603832a9716c3c914448be1ce095bb5a3b0d602d46e4
+	// foo-bar
+	// err: invalid signature
+	// err: invalid string
+	// err: signedstrings: FixedLength does not support caveats
+	// signedstrings: FixedLength does not support timestamps, salt, legacy signatures and version markers
+}
+
+func Example_sigFirst() {
+	conf := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Prefixes:  []string{"tok_"},
+		Timestamp: true,
+		SigFirst:  true,
+		Now:       func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	signed := conf.Sign("foo-bar")
+	fmt.Println(signed)
+	print(conf.Validate(signed))
+	print(conf.Validate(signed[:len(signed)-1]))
+	print(conf.Attenuate(signed, "x"))
+
+	// Output: @1700000000.e11de93548b69046562e7e5812f4cf8cc29173418f41a031b541dcf407e829a4-tok_foo-bar
+	// foo-bar
+	// err: invalid signature
+	// err: signedstrings: SigFirst does not support caveats
+}
+
+func Example_seps() {
+	old := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Sep:       "-",
+		Timestamp: true,
+		Now:       func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	conf := old
+	conf.Sep = ""
+	conf.Seps = []string{"~~", "-"}
+
+	signed := conf.Sign("foo-bar")
+	fmt.Println(signed)
+	print(conf.Validate(signed))
+	print(conf.Validate(old.Sign("foo~~bar")))
+	print(old.Validate(signed))
+
+	// Output: foo-bar~~@1700000000.a2c5a66dea91d8de42901f9813b3cbe2fafacb970e20eacb7a103775639de6ac
+	// foo-bar
+	// foo~~bar
+	// err: unacceptable algorithm
+}
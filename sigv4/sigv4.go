@@ -0,0 +1,393 @@
+// Package sigv4 verifies AWS Signature Version 4 requests, for building
+// S3-compatible and other AWS-style endpoints whose clients already speak
+// SigV4. Both the Authorization header and presigned URLs are supported.
+package sigv4
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	timeFormat      = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+var (
+	// Missing is returned for requests carrying no SigV4 authorization.
+	Missing = errors.New("missing authorization")
+	// Invalid is returned for malformed authorization.
+	Invalid = errors.New("invalid authorization")
+	// UnknownKey is returned for unknown access key IDs.
+	UnknownKey = errors.New("unknown access key")
+	// InvalidSig is returned when the signature does not match.
+	InvalidSig = errors.New("invalid signature")
+	// Expired is returned when the request time is out of tolerance or
+	// a presigned URL has expired.
+	Expired = errors.New("request expired")
+)
+
+// Verifier verifies SigV4-signed requests.
+type Verifier struct {
+	Region  string
+	Service string
+
+	// Secret returns the secret access key for an access key ID.
+	// See KeysSecret to use signedstrings keys.
+	Secret func(accessKeyID string) (secret string, ok bool)
+
+	// Tolerance is the maximum clock skew for header-signed requests.
+	// Defaults to 15 minutes, like AWS.
+	Tolerance time.Duration
+
+	// MaxBodySize limits how much of the body is read to verify its hash.
+	// Defaults to 10 MiB.
+	MaxBodySize int64
+
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Credentials derives an AWS-style access key ID and secret access key from
+// a signedstrings key, for handing out to clients.
+func Credentials(key []byte) (accessKeyID, secret string) {
+	mac := hmacSHA256(key, "signedstrings sigv4 secret")
+	return "AKSS" + strings.ToUpper(signedstrings.KeyID(key)), base64.StdEncoding.EncodeToString(mac[:30])
+}
+
+// KeysSecret returns a Verifier.Secret function accepting the Credentials
+// of any of the configuration's keys, so rotating Keys rotates credentials.
+func KeysSecret(conf *signedstrings.Configuration) func(accessKeyID string) (string, bool) {
+	return func(accessKeyID string) (string, bool) {
+		for _, key := range conf.ActiveKeys() {
+			if id, secret := Credentials(key); id == accessKeyID {
+				return secret, true
+			}
+		}
+		return "", false
+	}
+}
+
+// Verify checks the signature of the request and returns the access key ID
+// that signed it. The body is read and replaced with an in-memory copy
+// unless the client signed it as UNSIGNED-PAYLOAD. Streaming (chunk-signed)
+// payloads are rejected as Invalid.
+func (v *Verifier) Verify(r *http.Request) (string, error) {
+	a, err := parseAuth(r)
+	if err != nil {
+		return "", err
+	}
+	if a.region != v.Region || a.service != v.Service || a.terminator != "aws4_request" {
+		return "", fmt.Errorf("%w: wrong credential scope", Invalid)
+	}
+	t, err := time.Parse(timeFormat, a.amzDate)
+	if err != nil || a.date != a.amzDate[:8] {
+		return "", fmt.Errorf("%w: bad date", Invalid)
+	}
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if a.presigned {
+		if now.Before(t.Add(-v.tolerance())) || !now.Before(t.Add(a.expires)) {
+			return "", Expired
+		}
+	} else if d := now.Sub(t); d > v.tolerance() || d < -v.tolerance() {
+		return "", Expired
+	}
+
+	secret, ok := v.Secret(a.accessKeyID)
+	if !ok {
+		return "", UnknownKey
+	}
+
+	payloadHash := unsignedPayload
+	if !a.presigned {
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		if strings.HasPrefix(payloadHash, "STREAMING-") {
+			// chunk signatures are not verified, so the body would be unsigned
+			return "", fmt.Errorf("%w: streaming payloads are not supported", Invalid)
+		} else if payloadHash != unsignedPayload {
+			actual, err := v.hashBody(r)
+			if err != nil {
+				return "", err
+			}
+			if payloadHash != "" && payloadHash != actual {
+				return "", InvalidSig
+			}
+			payloadHash = actual
+		}
+	}
+
+	creq := canonicalRequest(r, v.Service, a.signedHeaders, payloadHash)
+	expected := signature(secret, a.amzDate, a.region, a.service, creq)
+	if !hmac.Equal([]byte(expected), []byte(a.signature)) {
+		return "", InvalidSig
+	}
+	return a.accessKeyID, nil
+}
+
+// Sign adds SigV4 headers to r the way AWS SDKs do, signing the whole
+// body. Mostly useful for tests and for Go clients of SigV4 endpoints.
+func Sign(r *http.Request, accessKeyID, secret, region, service string, t time.Time) error {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	amzDate := t.UTC().Format(timeFormat)
+	payloadHash := hashHex(body)
+	r.Header.Set("X-Amz-Date", amzDate)
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	creq := canonicalRequest(r, service, signedHeaders, payloadHash)
+	sig := signature(secret, amzDate, region, service, creq)
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s/%s/%s/aws4_request, SignedHeaders=%s, Signature=%s",
+		algorithm, accessKeyID, amzDate[:8], region, service, strings.Join(signedHeaders, ";"), sig))
+	return nil
+}
+
+// Presign adds SigV4 query parameters to r.URL, producing a presigned URL
+// valid for the given duration. The body is not signed.
+func Presign(r *http.Request, accessKeyID, secret, region, service string, t time.Time, expires time.Duration) {
+	amzDate := t.UTC().Format(timeFormat)
+	q := r.URL.Query()
+	q.Set("X-Amz-Algorithm", algorithm)
+	q.Set("X-Amz-Credential", accessKeyID+"/"+amzDate[:8]+"/"+region+"/"+service+"/aws4_request")
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+	q.Del("X-Amz-Signature")
+	r.URL.RawQuery = q.Encode()
+
+	creq := canonicalRequest(r, service, []string{"host"}, unsignedPayload)
+	r.URL.RawQuery += "&X-Amz-Signature=" + signature(secret, amzDate, region, service, creq)
+}
+
+// Middleware rejects requests that fail Verify with 403 Forbidden.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := v.Verify(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Verifier) tolerance() time.Duration {
+	if v.Tolerance == 0 {
+		return 15 * time.Minute
+	}
+	return v.Tolerance
+}
+
+func (v *Verifier) hashBody(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return hashHex(nil), nil
+	}
+	maxSize := v.MaxBodySize
+	if maxSize == 0 {
+		maxSize = 10 << 20
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > maxSize {
+		return "", fmt.Errorf("sigv4: request body exceeds %d bytes", maxSize)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return hashHex(body), nil
+}
+
+type auth struct {
+	accessKeyID, date, region, service, terminator string
+	amzDate                                        string
+	signedHeaders                                  []string
+	signature                                      string
+	presigned                                      bool
+	expires                                        time.Duration
+}
+
+func parseAuth(r *http.Request) (*auth, error) {
+	a := &auth{}
+	var credential, signedHeaders string
+	if h := r.Header.Get("Authorization"); h != "" {
+		params, ok := strings.CutPrefix(h, algorithm+" ")
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported algorithm", Invalid)
+		}
+		for _, p := range strings.Split(params, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			switch k {
+			case "Credential":
+				credential = v
+			case "SignedHeaders":
+				signedHeaders = v
+			case "Signature":
+				a.signature = v
+			}
+		}
+		a.amzDate = r.Header.Get("X-Amz-Date")
+	} else if q := r.URL.Query(); q.Get("X-Amz-Signature") != "" {
+		if q.Get("X-Amz-Algorithm") != algorithm {
+			return nil, fmt.Errorf("%w: unsupported algorithm", Invalid)
+		}
+		credential, signedHeaders, a.signature = q.Get("X-Amz-Credential"), q.Get("X-Amz-SignedHeaders"), q.Get("X-Amz-Signature")
+		a.amzDate = q.Get("X-Amz-Date")
+		secs, err := strconv.Atoi(q.Get("X-Amz-Expires"))
+		if err != nil || secs < 1 || secs > 604800 {
+			return nil, fmt.Errorf("%w: bad expiration", Invalid)
+		}
+		a.presigned, a.expires = true, time.Duration(secs)*time.Second
+	} else {
+		return nil, Missing
+	}
+
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || signedHeaders == "" || a.signature == "" || len(a.amzDate) != len(timeFormat) {
+		return nil, Invalid
+	}
+	a.accessKeyID, a.date, a.region, a.service, a.terminator = parts[0], parts[1], parts[2], parts[3], parts[4]
+	a.signedHeaders = strings.Split(signedHeaders, ";")
+	if !containsString(a.signedHeaders, "host") {
+		return nil, fmt.Errorf("%w: host must be signed", Invalid)
+	}
+	return a, nil
+}
+
+func canonicalRequest(r *http.Request, service string, signedHeaders []string, payloadHash string) string {
+	var buf strings.Builder
+	buf.WriteString(r.Method)
+	buf.WriteByte('\n')
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	path = uriEncode(path, false)
+	if service != "s3" {
+		path = uriEncode(path, false)
+	}
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+
+	// sorted by key, then value, so that "a" comes before "a-"
+	var pairs [][2]string
+	for k, vs := range r.URL.Query() {
+		if k == "X-Amz-Signature" {
+			continue
+		}
+		for _, v := range vs {
+			pairs = append(pairs, [2]string{uriEncode(k, true), uriEncode(v, true)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	for i, p := range pairs {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(p[0])
+		buf.WriteByte('=')
+		buf.WriteString(p[1])
+	}
+	buf.WriteByte('\n')
+
+	for _, name := range signedHeaders {
+		var values []string
+		if name == "host" {
+			values = []string{r.Host}
+		} else {
+			values = r.Header.Values(name)
+		}
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		for i, v := range values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strings.Join(strings.Fields(v), " "))
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Join(signedHeaders, ";"))
+	buf.WriteByte('\n')
+	buf.WriteString(payloadHash)
+	return buf.String()
+}
+
+// uriEncode implements the UriEncode function of the SigV4 spec.
+func uriEncode(s string, encodeSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func signature(secret, amzDate, region, service, canonicalRequest string) string {
+	date := amzDate[:8]
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	sts := algorithm + "\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+	return hex.EncodeToString(hmacSHA256(signingKey(secret, date, region, service), sts))
+}
+
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
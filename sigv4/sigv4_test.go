@@ -0,0 +1,149 @@
+package sigv4_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/sigv4"
+)
+
+var exampleKey = []byte("\xd8\x50\xaf\x43\x5c\x7c\x82\x6b\xfb\x8e\x9d\x15\x35\x55\x45\x64\x2e\xd5\x4c\x0c\x0d\x54\x2c\x34\x4a\xdd\xa7\x84\x7b\x4b\x81\xc2")
+
+// TestVerify_awsVector checks the example from the AWS SigV4 documentation.
+func TestVerify_awsVector(t *testing.T) {
+	r := httptest.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	r.Header.Set("X-Amz-Date", "20150830T123600Z")
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7")
+
+	v := &sigv4.Verifier{
+		Region:  "us-east-1",
+		Service: "iam",
+		Secret: func(id string) (string, bool) {
+			return "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", id == "AKIDEXAMPLE"
+		},
+		Now: func() time.Time { return time.Date(2015, 8, 30, 12, 40, 0, 0, time.UTC) },
+	}
+	if id, err := v.Verify(r); err != nil || id != "AKIDEXAMPLE" {
+		t.Fatalf("Verify = %q, %v", id, err)
+	}
+
+	r.URL.RawQuery = "Action=ListUsers&Version=2010-05-09"
+	if _, err := v.Verify(r); err != sigv4.InvalidSig {
+		t.Errorf("Verify(tampered) = %v", err)
+	}
+}
+
+// TestVerify_queryOrder checks that query parameters are sorted by key, then
+// value, so that a key sorts before keys it is a prefix of. The signature is
+// computed independently following the AWS documentation.
+func TestVerify_queryOrder(t *testing.T) {
+	r := httptest.NewRequest("GET", "https://iam.amazonaws.com/?a-=2&a=1", nil)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	r.Header.Set("X-Amz-Date", "20150830T123600Z")
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=a8999d9c54c4eae9ff72d40044ab2a6bbea3195981b2c0efff5683de6cc5a385")
+
+	v := &sigv4.Verifier{
+		Region:  "us-east-1",
+		Service: "iam",
+		Secret: func(id string) (string, bool) {
+			return "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", id == "AKIDEXAMPLE"
+		},
+		Now: func() time.Time { return time.Date(2015, 8, 30, 12, 40, 0, 0, time.UTC) },
+	}
+	if id, err := v.Verify(r); err != nil || id != "AKIDEXAMPLE" {
+		t.Fatalf("Verify = %q, %v", id, err)
+	}
+}
+
+func TestVerify_keys(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	v := &sigv4.Verifier{
+		Region:  "auto",
+		Service: "s3",
+		Secret:  sigv4.KeysSecret(conf),
+		Now:     func() time.Time { return now },
+	}
+	id, secret := sigv4.Credentials(exampleKey)
+
+	newRequest := func(body string) *http.Request {
+		r := httptest.NewRequest("PUT", "http://storage.example.com/bucket/some%20file.txt?x-id=PutObject", strings.NewReader(body))
+		must(t, sigv4.Sign(r, id, secret, "auto", "s3", now))
+		return r
+	}
+
+	r := newRequest("hello")
+	if got, err := v.Verify(r); err != nil || got != id {
+		t.Fatalf("Verify = %q, %v", got, err)
+	}
+
+	r = newRequest("hello")
+	r.Body = http.NoBody
+	if _, err := v.Verify(r); err != sigv4.InvalidSig {
+		t.Errorf("Verify(replaced body) = %v", err)
+	}
+
+	r = newRequest("hello")
+	r.Header.Set("X-Amz-Content-Sha256", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")
+	if _, err := v.Verify(r); !errors.Is(err, sigv4.Invalid) {
+		t.Errorf("Verify(streaming) = %v", err)
+	}
+
+	r = newRequest("hello")
+	must(t, sigv4.Sign(r, "AKSSFFFFFFFFFFFFFFFF", secret, "auto", "s3", now))
+	if _, err := v.Verify(r); err != sigv4.UnknownKey {
+		t.Errorf("Verify(unknown key) = %v", err)
+	}
+
+	r = newRequest("hello")
+	must(t, sigv4.Sign(r, id, secret, "us-east-1", "s3", now))
+	if _, err := v.Verify(r); !errors.Is(err, sigv4.Invalid) {
+		t.Errorf("Verify(wrong region) = %v", err)
+	}
+
+	r = newRequest("hello")
+	must(t, sigv4.Sign(r, id, secret, "auto", "s3", now.Add(-time.Hour)))
+	if _, err := v.Verify(r); err != sigv4.Expired {
+		t.Errorf("Verify(stale) = %v", err)
+	}
+
+	if _, err := v.Verify(httptest.NewRequest("GET", "/", nil)); err != sigv4.Missing {
+		t.Errorf("Verify(unsigned) = %v", err)
+	}
+}
+
+func TestVerify_presigned(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	id, secret := sigv4.Credentials(exampleKey)
+	v := &sigv4.Verifier{
+		Region:  "auto",
+		Service: "s3",
+		Secret:  sigv4.KeysSecret(&signedstrings.Configuration{Keys: [][]byte{exampleKey}}),
+		Now:     func() time.Time { return now },
+	}
+
+	r := httptest.NewRequest("GET", "http://storage.example.com/bucket/file.txt?versionId=3", nil)
+	sigv4.Presign(r, id, secret, "auto", "s3", now, time.Minute)
+	r = httptest.NewRequest("GET", r.URL.String(), nil)
+	if got, err := v.Verify(r); err != nil || got != id {
+		t.Fatalf("Verify = %q, %v", got, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := v.Verify(r); err != sigv4.Expired {
+		t.Errorf("Verify(expired) = %v", err)
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build go1.21
+
+package signedstrings
+
+import "log/slog"
+
+// LogTo sets ValidationFailed and KeysRotated to log to logger. Entries carry
+// the Reason, the Fingerprint and the prefix of the string, never the string
+// or its data. Call it before Compile.
+func (conf *Configuration) LogTo(logger *slog.Logger) {
+	conf.ValidationFailed = func(signed string, err error) {
+		attrs := []any{
+			slog.String("reason", Reason(err)),
+			slog.String("fingerprint", Fingerprint(signed)),
+		}
+		if insp, ierr := conf.Inspect(signed); ierr == nil {
+			attrs = append(attrs, slog.String("prefix", insp.Prefix))
+		}
+		logger.Warn("signedstrings: validation failed", attrs...)
+	}
+	conf.KeysRotated = func(keyIDs []string) {
+		logger.Info("signedstrings: keys rotated", slog.Any("key_ids", keyIDs))
+	}
+}
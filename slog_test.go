@@ -0,0 +1,37 @@
+//go:build go1.21
+
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"os"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_LogTo() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	conf := &signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"tok_"},
+	}
+	conf.LogTo(logger)
+	compiled := must(conf.Compile())
+
+	signed := compiled.Sign("secret")
+	compiled.Validate(signed[:len(signed)-1] + "0")
+	compiled.Validate("garbage")
+	compiled.Rotate(must(hex.DecodeString("283d54389c394ed33ba4146eff7b4133f7e393cb905d089a06798456a1cb7dcd")))
+
+	// Output: level=WARN msg="signedstrings: validation failed" reason=invalid_signature fingerprint=8bdf19c4a7590c54 prefix=tok_
+	// level=WARN msg="signedstrings: validation failed" reason=invalid fingerprint=795b6904e54f8241
+	// level=INFO msg="signedstrings: keys rotated" key_ids="[2dd6cc5a150280a3 a814acf20ffba3c8]"
+}
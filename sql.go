@@ -0,0 +1,46 @@
+package signedstrings
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// SignedText is a tamper-evident database column: it implements
+// driver.Valuer and sql.Scanner, storing Data signed and validating it when
+// read back. Set Conf before scanning. Like sql.NullString, Valid is false
+// for NULL.
+type SignedText struct {
+	Conf  *Configuration
+	Data  string
+	Valid bool
+}
+
+// Value signs Data.
+func (t SignedText) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.Conf.TrySign(t.Data)
+}
+
+// Scan validates a stored value and sets Data.
+func (t *SignedText) Scan(src any) error {
+	var signed string
+	switch v := src.(type) {
+	case nil:
+		t.Data, t.Valid = "", false
+		return nil
+	case string:
+		signed = v
+	case []byte:
+		signed = string(v)
+	default:
+		return fmt.Errorf("signedstrings: cannot scan %T into SignedText", src)
+	}
+	data, err := t.Conf.Validate(signed)
+	if err != nil {
+		return err
+	}
+	t.Data, t.Valid = data, true
+	return nil
+}
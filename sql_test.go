@@ -0,0 +1,29 @@
+package signedstrings_test
+
+import (
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleSignedText() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+
+	// written by db.Exec("INSERT ...", signedstrings.SignedText{...})
+	print(signedstrings.SignedText{Conf: conf, Data: "foo", Valid: true}.Value())
+	print(signedstrings.SignedText{Conf: conf}.Value())
+
+	// read by db.QueryRow("SELECT ...").Scan(&text)
+	text := signedstrings.SignedText{Conf: conf}
+	fmt.Println(text.Scan([]byte("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334")), text.Data, text.Valid)
+	fmt.Println(text.Scan(nil), text.Data, text.Valid)
+	fmt.Println(text.Scan("bar-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"), text.Valid)
+	fmt.Println(text.Scan(42))
+
+	// Output: foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334
+	// <nil>
+	// <nil> foo true
+	// <nil>  false
+	// invalid signature false
+	// signedstrings: cannot scan int into SignedText
+}
@@ -0,0 +1,191 @@
+// Package sqlrevocation provides a signedstrings.RevocationChecker backed by
+// a database/sql table of revoked token fingerprints.
+package sqlrevocation
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// DefaultTable is the name of the table used when Store.Table is empty.
+const DefaultTable = "revoked_tokens"
+
+// batchSize limits the number of placeholders per query.
+const batchSize = 100
+
+// Store records revoked tokens by their fingerprint (see
+// Configuration.RevocationFingerprint) in a table created by CreateTable. Set it as
+// Configuration.Revocations. Lookups are batched and optionally cached.
+type Store struct {
+	DB *sql.DB
+
+	// Table defaults to DefaultTable.
+	Table string
+
+	// Placeholder returns the n-th (1-based) query placeholder. Defaults to
+	// "?"; use Dollar for PostgreSQL.
+	Placeholder func(n int) string
+
+	// CacheTTL is how long lookup results are remembered, trading a delay
+	// before revocations take effect on other instances for fewer queries.
+	// Zero disables caching.
+	CacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+	swept time.Time
+}
+
+type cached struct {
+	revoked bool
+	until   time.Time
+}
+
+var _ signedstrings.RevocationChecker = (*Store)(nil)
+
+// Dollar returns PostgreSQL-style placeholders: $1, $2 and so on.
+func Dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Schema returns the CREATE TABLE statement for the given table, portable
+// across SQLite, PostgreSQL and MySQL. Expiration times are Unix seconds.
+func Schema(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + " (fingerprint VARCHAR(32) NOT NULL PRIMARY KEY, expires_at BIGINT NOT NULL)"
+}
+
+// CreateTable creates the table if it does not exist.
+func (s *Store) CreateTable(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, Schema(s.table()))
+	return err
+}
+
+// Revoke records the token with the given fingerprint, obtained from
+// Configuration.RevocationFingerprint, as revoked until it expires; pass the
+// time after which the token is rejected anyway, or a far future time if it
+// never expires.
+func (s *Store) Revoke(ctx context.Context, fp string, expires time.Time) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+s.table()+" WHERE fingerprint = "+s.placeholder(1), fp); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO "+s.table()+" (fingerprint, expires_at) VALUES ("+s.placeholder(1)+", "+s.placeholder(2)+")", fp, expires.Unix()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.cache, fp)
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteExpired removes revocations of tokens that have expired, and
+// returns how many were removed. Run it periodically.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.DB.ExecContext(ctx, "DELETE FROM "+s.table()+" WHERE expires_at < "+s.placeholder(1), time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RevokedAmong implements signedstrings.RevocationChecker.
+func (s *Store) RevokedAmong(ctx context.Context, fingerprints []string) (map[string]bool, error) {
+	result := make(map[string]bool, len(fingerprints))
+	now := time.Now()
+	var missing []string
+	s.mu.Lock()
+	for _, fp := range fingerprints {
+		if c, ok := s.cache[fp]; ok && now.Before(c.until) {
+			result[fp] = c.revoked
+		} else {
+			missing = append(missing, fp)
+		}
+	}
+	s.mu.Unlock()
+
+	for len(missing) > 0 {
+		batch := missing
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		missing = missing[len(batch):]
+		if err := s.lookup(ctx, batch, result); err != nil {
+			return nil, err
+		}
+		if s.CacheTTL > 0 {
+			s.remember(batch, result, now)
+		}
+	}
+	return result, nil
+}
+
+func (s *Store) lookup(ctx context.Context, fingerprints []string, result map[string]bool) error {
+	placeholders := make([]string, len(fingerprints))
+	args := make([]any, len(fingerprints))
+	for i, fp := range fingerprints {
+		placeholders[i] = s.placeholder(i + 1)
+		args[i] = fp
+	}
+	rows, err := s.DB.QueryContext(ctx, "SELECT fingerprint FROM "+s.table()+" WHERE fingerprint IN ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for _, fp := range fingerprints {
+		result[fp] = false
+	}
+	for rows.Next() {
+		var fp string
+		if err := rows.Scan(&fp); err != nil {
+			return err
+		}
+		result[fp] = true
+	}
+	return rows.Err()
+}
+
+func (s *Store) remember(fingerprints []string, result map[string]bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache == nil {
+		s.cache = make(map[string]cached)
+	}
+	if now.Sub(s.swept) > time.Minute {
+		for fp, c := range s.cache {
+			if !now.Before(c.until) {
+				delete(s.cache, fp)
+			}
+		}
+		s.swept = now
+	}
+	for _, fp := range fingerprints {
+		s.cache[fp] = cached{result[fp], now.Add(s.CacheTTL)}
+	}
+}
+
+func (s *Store) table() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultTable
+}
+
+func (s *Store) placeholder(n int) string {
+	if s.Placeholder != nil {
+		return s.Placeholder(n)
+	}
+	return "?"
+}
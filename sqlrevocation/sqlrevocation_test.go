@@ -0,0 +1,78 @@
+package sqlrevocation_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/sqlrevocation"
+	_ "modernc.org/sqlite"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // every connection gets its own in-memory database
+
+	store := &sqlrevocation.Store{DB: db, CacheTTL: time.Minute}
+	if err := store.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := hex.DecodeString("d850af431064164d9a73891fa0a257ba91e5cb18a67de07d3507b8ccdc8781c2")
+	conf := &signedstrings.Configuration{Keys: signedstrings.Keys{key}, Revocations: store}
+
+	a, b := conf.Sign("a"), conf.Sign("b")
+	if err := store.Revoke(ctx, fingerprint(t, conf, a), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Revoke(ctx, fingerprint(t, conf, a), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("revoking again: %v", err)
+	}
+	if _, err := conf.Validate(a); !errors.Is(err, signedstrings.Revoked) {
+		t.Errorf("Validate(revoked) = %v, wanted %v", err, signedstrings.Revoked)
+	}
+	if data, err := conf.Validate(b); err != nil || data != "b" {
+		t.Errorf("Validate(b) = %q, %v", data, err)
+	}
+
+	// b is cached as not revoked
+	if _, err := db.Exec("INSERT INTO revoked_tokens VALUES (?, ?)", fingerprint(t, conf, b), time.Now().Add(-time.Hour).Unix()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conf.Validate(b); err != nil {
+		t.Errorf("Validate(b) after revoking behind the cache = %v", err)
+	}
+
+	fps := make([]string, 250)
+	for i := range fps {
+		fps[i] = signedstrings.Fingerprint(strconv.Itoa(i))
+	}
+	fps[200] = fingerprint(t, conf, a)
+	revoked, err := store.RevokedAmong(ctx, fps)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(revoked) != len(fps) || !revoked[fps[200]] || revoked[fps[0]] {
+		t.Errorf("RevokedAmong returned %d results, [200] = %v, [0] = %v", len(revoked), revoked[fps[200]], revoked[fps[0]])
+	}
+
+	if n, err := store.DeleteExpired(ctx); err != nil || n != 1 {
+		t.Errorf("DeleteExpired = %d, %v", n, err)
+	}
+}
+
+func fingerprint(t *testing.T, conf *signedstrings.Configuration, signed string) string {
+	fp, err := conf.RevocationFingerprint(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
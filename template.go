@@ -0,0 +1,33 @@
+package signedstrings
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// FuncMap returns html/template functions producing signed values:
+//
+//	{{sign .ID}}                    the signed string
+//	{{signedField "id" .ID}}        a hidden form field with the signed value
+//	{{signedURL "/download?f=a"}}   the URL with a signature, see SignURL
+//
+// Validate the results with Validate, FormValue and ValidateURL.
+func (conf *Configuration) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"sign": conf.TrySign,
+		"signedField": func(name, value string) (template.HTML, error) {
+			signed, err := conf.TrySign(value)
+			if err != nil {
+				return "", err
+			}
+			return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(name) + `" value="` + template.HTMLEscapeString(signed) + `">`), nil
+		},
+		"signedURL": conf.SignURL,
+	}
+}
+
+// FormValue validates the named form field, as produced by signedField
+// (see FuncMap), and returns the original value.
+func (conf *Configuration) FormValue(r *http.Request, name string) (string, error) {
+	return conf.Validate(r.FormValue(name))
+}
@@ -0,0 +1,38 @@
+package signedstrings_test
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_FuncMap() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	tmpl := template.Must(template.New("").Funcs(conf.FuncMap()).Parse(
+		`<form action="{{signedURL "/orders?id=42"}}">{{signedField "user" .}}</form> {{sign .}}`))
+	tmpl.Execute(os.Stdout, "foo")
+
+	// Output: <form action="/orders?id=42&amp;sig=248603f1334956e036bf2b2d929a144ed4eb5bb2f917cc255bb31facb766222b"><input type="hidden" name="user" value="foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"></form> foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334
+}
+
+func TestFormValue(t *testing.T) {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	form := url.Values{"user": {conf.Sign("foo")}, "forged": {"bar-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"}}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if v, err := conf.FormValue(r, "user"); err != nil || v != "foo" {
+		t.Errorf("FormValue(user) = %q, %v", v, err)
+	}
+	if _, err := conf.FormValue(r, "forged"); err != signedstrings.InvalidSig {
+		t.Errorf("FormValue(forged) = %v", err)
+	}
+	if _, err := conf.FormValue(r, "missing"); err != signedstrings.Invalid {
+		t.Errorf("FormValue(missing) = %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package signedstrings
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// tenantSep separates the tenant field from the data, see Tenants.
+const tenantSep = ":"
+
+// Tenants signs and validates strings with per-tenant keys, e.g. per-customer
+// keys in SaaS deployments, without a Configuration per tenant.
+//
+// By default, Sign records the tenant in a field in front of the data,
+// covered by the signature: TOKEN-acme:foo-1c54...7a1e. Set Tenant to
+// identify the tenant some other way, e.g. from the prefix.
+type Tenants struct {
+	// Conf holds the settings shared by all tenants; its keys are not used.
+	Conf *Configuration
+
+	// Keys returns the keys of a tenant. The first one signs, all are
+	// accepted. Called on every operation, so keys can change at any time.
+	Keys func(tenant string) (Keys, error)
+
+	// Tenant, if set, identifies the tenant of a string being validated, and
+	// Sign no longer adds the tenant field.
+	Tenant func(signed string) (string, error)
+
+	confs sync.Map // tenant -> *tenantConf
+}
+
+type tenantConf struct {
+	keys Keys
+	conf *Configuration
+}
+
+// Sign signs data with the keys of the given tenant.
+func (t *Tenants) Sign(tenant, data string) (string, error) {
+	conf, err := t.conf(tenant)
+	if err != nil {
+		return "", err
+	}
+	if t.Tenant == nil {
+		if tenant == "" || strings.Contains(tenant, tenantSep) {
+			return "", errors.New("signedstrings: invalid tenant " + tenant)
+		}
+		data = tenant + tenantSep + data
+	}
+	return conf.TrySign(data)
+}
+
+// Validate identifies the tenant of the signed string, verifies the
+// signature using that tenant's keys, and returns the tenant and the data.
+func (t *Tenants) Validate(signed string) (tenant, data string, err error) {
+	if t.Tenant != nil {
+		tenant, err = t.Tenant(signed)
+	} else {
+		tenant, err = t.tenantField(signed)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	conf, err := t.conf(tenant)
+	if err != nil {
+		return "", "", err
+	}
+	data, err = conf.Validate(signed)
+	if err != nil {
+		return "", "", err
+	}
+	if t.Tenant == nil {
+		_, data, _ = strings.Cut(data, tenantSep)
+	}
+	return tenant, data, nil
+}
+
+func (t *Tenants) tenantField(signed string) (string, error) {
+	tok, ok := t.Conf.parse(signed)
+	if !ok {
+		return "", Invalid
+	}
+	_, data, idx := t.Conf.cutPrefix(tok.msg)
+	if idx < 0 {
+		return "", Invalid
+	}
+	tenant, _, found := strings.Cut(data, tenantSep)
+	if !found || tenant == "" {
+		return "", Invalid
+	}
+	return tenant, nil
+}
+
+// conf returns a compiled configuration for the tenant, reusing the previous
+// one while the tenant's keys stay the same.
+func (t *Tenants) conf(tenant string) (*Configuration, error) {
+	keys, err := t.Keys(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := t.confs.Load(tenant); ok {
+		if tc := v.(*tenantConf); keysEqual(tc.keys, keys) {
+			return tc.conf, nil
+		}
+	}
+	conf, err := t.Conf.derive(keys, t.Conf.Prefixes).Compile()
+	if err != nil {
+		return nil, err
+	}
+	t.confs.Store(tenant, &tenantConf{conf.Keys, conf})
+	return conf, nil
+}
+
+func keysEqual(a, b Keys) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
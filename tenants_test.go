@@ -0,0 +1,66 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+var tenantKeys = map[string]signedstrings.Keys{
+	"acme":   {exampleKey},
+	"globex": {must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))},
+}
+
+func lookupTenantKeys(tenant string) (signedstrings.Keys, error) {
+	if keys, ok := tenantKeys[tenant]; ok {
+		return keys, nil
+	}
+	return nil, errors.New("unknown tenant " + tenant)
+}
+
+func ExampleTenants() {
+	tenants := &signedstrings.Tenants{
+		Conf: &signedstrings.Configuration{Prefixes: []string{"TOKEN-"}},
+		Keys: lookupTenantKeys,
+	}
+
+	signed := must(tenants.Sign("acme", "foo"))
+	fmt.Println(signed)
+	printTenant(tenants.Validate(signed))
+
+	// switching the tenant field invalidates the signature
+	printTenant(tenants.Validate(strings.Replace(signed, "acme", "globex", 1)))
+	printTenant(tenants.Validate(strings.Replace(signed, "acme", "initech", 1)))
+	printTenant(tenants.Validate("TOKEN-foo-4bc019e2218479926f27694a281b8b2af30f86f5f522d0bbde31ab19bc730f39"))
+
+	// Output: TOKEN-acme:foo-a6946d3d7ff1a7dc33a4ef548666e12383cfe211187b2824c680812b8be8c110
+	// acme foo
+	// err: invalid signature
+	// err: unknown tenant initech
+	// err: invalid string
+}
+
+func ExampleTenants_prefix() {
+	// the tenant is identified by the prefix, e.g. acme_foo-1c54...7a1e
+	tenants := &signedstrings.Tenants{
+		Conf: &signedstrings.Configuration{Prefixes: []string{""}},
+		Keys: lookupTenantKeys,
+		Tenant: func(signed string) (string, error) {
+			tenant, _, _ := strings.Cut(signed, "_")
+			return tenant, nil
+		},
+	}
+	signed := must(tenants.Sign("globex", "globex_foo"))
+	fmt.Println(signed)
+	printTenant(tenants.Validate(signed))
+
+	// Output: globex_foo-14d6bc9979d7e2e0fcaa7092b423b14e96132f2a78ece8d9512c045ee72a5ca2
+	// globex globex_foo
+}
+
+func printTenant(tenant, data string, err error) {
+	print(tenant+" "+data, err)
+}
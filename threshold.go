@@ -0,0 +1,98 @@
+package signedstrings
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Threshold is a validation policy requiring that at least K of the Signers
+// have signed a string, for operations that need multi-party authorization.
+//
+// The first signer signs the data with Sign, and each subsequent one adds
+// a signature on top with AddSignature, in any order.
+type Threshold struct {
+	K       int
+	Signers []*Configuration
+}
+
+// AddSignature verifies the signatures already on the string, and adds
+// a signature by the given signer, which must be one of the Signers and
+// must not have signed the string yet.
+func (t *Threshold) AddSignature(signed string, signer *Configuration) (string, error) {
+	_, signers, err := t.peel(signed)
+	if err != nil {
+		return "", err
+	}
+	idx := -1
+	for i, s := range t.Signers {
+		if s == signer {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return "", errors.New("signedstrings: not one of the threshold signers")
+	}
+	for _, i := range signers {
+		if i == idx {
+			return "", errors.New("signedstrings: already signed by this signer")
+		}
+	}
+	return signer.TrySign(signed)
+}
+
+// Validate verifies that at least K distinct Signers have signed the string,
+// and returns the original data.
+func (t *Threshold) Validate(signed string) (string, error) {
+	data, _, err := t.ValidSigners(signed)
+	return data, err
+}
+
+// ValidSigners is like Validate, but also returns the indices of the Signers
+// whose signatures were verified, outermost first.
+func (t *Threshold) ValidSigners(signed string) (string, []int, error) {
+	data, signers, err := t.peel(signed)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(signers) < t.K {
+		return "", nil, fmt.Errorf("%w: %d of %d required signatures", InvalidSig, len(signers), t.K)
+	}
+	return data, signers, nil
+}
+
+// peel removes valid signatures from the outside in, each one by a signer
+// that has not been seen yet, until none of the remaining signers matches.
+func (t *Threshold) peel(signed string) (string, []int, error) {
+	if t.K < 1 || t.K > len(t.Signers) {
+		panic("signedstrings: invalid threshold")
+	}
+	used := make([]bool, len(t.Signers))
+	var signers []int
+	for {
+		found := false
+		for i, s := range t.Signers {
+			if used[i] {
+				continue
+			}
+			inner, err := s.Validate(signed)
+			if err == nil {
+				used[i], found, signed = true, true, inner
+				signers = append(signers, i)
+				break
+			} else if !isValidationError(err) {
+				return "", nil, err
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if len(signers) == 0 {
+		return "", nil, InvalidSig
+	}
+	return signed, signers, nil
+}
+
+func isValidationError(err error) bool {
+	return errors.Is(err, Invalid) || errors.Is(err, InvalidSig) || errors.Is(err, InvalidAlg) || errors.Is(err, InvalidVersion) || errors.Is(err, UnsatisfiedCaveat) || errors.Is(err, Expired)
+}
@@ -0,0 +1,36 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+var thirdKey = must(hex.DecodeString("283d54389c394ed33ba4146eff7b4133f7e393cb905d089a06798456a1cb7dcd"))
+
+func ExampleThreshold() {
+	alice := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	bob := &signedstrings.Configuration{Keys: [][]byte{gatewayKey}}
+	carol := &signedstrings.Configuration{Keys: [][]byte{thirdKey}}
+	policy := &signedstrings.Threshold{
+		K:       2,
+		Signers: []*signedstrings.Configuration{alice, bob, carol},
+	}
+
+	signed := carol.Sign("payout:42")
+	print(policy.Validate(signed))
+
+	signed = must(policy.AddSignature(signed, alice))
+	print(policy.Validate(signed))
+	fmt.Println(policy.ValidSigners(signed))
+
+	print(policy.AddSignature(signed, carol))
+	print(policy.Validate("payout:42-1111111111111111111111111111111111111111111111111111111111111111"))
+
+	// Output: err: invalid signature: 1 of 2 required signatures
+	// payout:42
+	// payout:42 [0 2] <nil>
+	// err: signedstrings: already signed by this signer
+	// err: invalid signature
+}
@@ -0,0 +1,39 @@
+package signedstrings
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// tokenHashLabel derives token hashing keys from the configured keys.
+const tokenHashLabel = "signedstrings token hash"
+
+// HashToken returns the value to store in a database instead of an issued
+// token: a hex-encoded HMAC keyed with a key derived from the first
+// configured key. A leaked database then yields no usable tokens, and
+// without the keys the hashes cannot be checked against guesses.
+// Use MatchTokenHash to compare. Panics if an external key fails.
+func (conf *Configuration) HashToken(token string) string {
+	return conf.keyedHashes(tokenHashLabel, token, 1)[0]
+}
+
+// MatchTokenHash returns whether stored is the HashToken of token under any
+// of the configured keys, so hashes stored before a key rotation still match.
+// Comparison is constant-time.
+func (conf *Configuration) MatchTokenHash(token, stored string) bool {
+	var match int
+	for _, h := range conf.keyedHashes(tokenHashLabel, token, -1) {
+		match |= subtle.ConstantTimeCompare([]byte(h), []byte(stored))
+	}
+	return match == 1
+}
+
+// Fingerprint returns a short identifier of a token that is safe to log and
+// paste into support tickets: the first 8 bytes of its SHA-256 hash,
+// hex-encoded. The signature makes tokens unguessable, so the fingerprint
+// cannot be reversed, but it still correlates log entries about one token.
+func Fingerprint(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:8])
+}
@@ -0,0 +1,32 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_HashToken() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	token := conf.Sign("session:42")
+	stored := conf.HashToken(token)
+	fmt.Println(stored)
+	fmt.Println(conf.MatchTokenHash(token, stored), conf.MatchTokenHash(token+"x", stored))
+
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	conf.Keys = [][]byte{newKey, exampleKey}
+	fmt.Println(conf.MatchTokenHash(token, stored), conf.HashToken(token) == stored)
+
+	// Output: 57b5462e4bd4caff611fb2d09078acf90dcad9f5275bad4db1c77947be1f3fb2
+	// true false
+	// true false
+}
+
+func ExampleFingerprint() {
+	fmt.Println(signedstrings.Fingerprint("foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0334"))
+
+	// Output: ed34b4aff2885c0d
+}
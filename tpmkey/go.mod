@@ -0,0 +1,14 @@
+module github.com/andreyvit/signedstrings/tpmkey
+
+// Unlike the other modules, this one needs Go 1.22: go-tpm added
+// linuxtpm, Hmac and HmacStart in v0.9.1, which requires it.
+go 1.22
+
+require (
+	github.com/andreyvit/signedstrings v0.0.0-00010101000000-000000000000
+	github.com/google/go-tpm v0.9.8
+)
+
+require golang.org/x/sys v0.8.0 // indirect
+
+replace github.com/andreyvit/signedstrings => ../
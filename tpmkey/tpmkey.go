@@ -0,0 +1,152 @@
+// Package tpmkey loads signedstrings keys from a TPM 2.0, for single-host
+// deployments that want keys to be unusable if the disk is copied elsewhere.
+//
+// Two modes are supported: UnsealKeys unseals key material at startup and
+// returns it for Configuration.Keys, while Key keeps an HMAC key inside
+// the TPM and is used via Configuration.ExternalKeys.
+package tpmkey
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpm2/transport"
+	"github.com/google/go-tpm/tpm2/transport/linuxtpm"
+)
+
+// DefaultDevice is the Linux in-kernel TPM resource manager.
+const DefaultDevice = "/dev/tpmrm0"
+
+// maxBuffer is the largest message TPM2_HMAC accepts in one go
+// (MAX_DIGEST_BUFFER); longer ones go through an HMAC sequence.
+const maxBuffer = 1024
+
+var hashAlgs = map[string]tpm2.TPMIAlgHash{
+	"HS256": tpm2.TPMAlgSHA256,
+	"HS384": tpm2.TPMAlgSHA384,
+	"HS512": tpm2.TPMAlgSHA512,
+}
+
+// UnsealKeys opens the TPM device and unseals the keys stored at the given
+// persistent handles, in order. The result is meant for Configuration.Keys.
+func UnsealKeys(device string, auth []byte, handles ...tpm2.TPMHandle) (signedstrings.Keys, error) {
+	tpm, err := linuxtpm.Open(device)
+	if err != nil {
+		return nil, fmt.Errorf("tpmkey: %w", err)
+	}
+	defer tpm.Close()
+
+	keys := make(signedstrings.Keys, 0, len(handles))
+	for _, h := range handles {
+		key, err := Unseal(tpm, h, auth)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Unseal returns the data sealed in the object at the given handle.
+func Unseal(tpm transport.TPM, handle tpm2.TPMHandle, auth []byte) ([]byte, error) {
+	name, err := readName(tpm, handle)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := tpm2.Unseal{
+		ItemHandle: tpm2.AuthHandle{Handle: handle, Name: name, Auth: tpm2.PasswordAuth(auth)},
+	}.Execute(tpm)
+	if err != nil {
+		return nil, fmt.Errorf("tpmkey: unseal 0x%x: %w", uint32(handle), err)
+	}
+	return rsp.OutData.Buffer, nil
+}
+
+// Key is an HMAC key that lives inside the TPM. Implements signedstrings.ExternalKey.
+type Key struct {
+	TPM    transport.TPM
+	Handle tpm2.TPMHandle
+	Auth   []byte
+
+	mu   sync.Mutex      // serializes TPM sessions and guards name
+	name *tpm2.TPM2BName // cached after the first successful read
+}
+
+// readName returns the name of the key object, reading it on first use.
+// Failures are not cached, so a transient TPM error is retried next time.
+// Must be called with k.mu held.
+func (k *Key) readName() (tpm2.TPM2BName, error) {
+	if k.name == nil {
+		name, err := readName(k.TPM, k.Handle)
+		if err != nil {
+			return tpm2.TPM2BName{}, err
+		}
+		k.name = &name
+	}
+	return *k.name, nil
+}
+
+// MAC asks the TPM to compute the HMAC of message. Calls are serialized, so
+// that concurrent HMAC sequences do not interleave on a shared connection.
+func (k *Key) MAC(alg *signedstrings.Algorithm, message []byte) ([]byte, error) {
+	hashAlg, ok := hashAlgs[alg.Name]
+	if !ok {
+		return nil, fmt.Errorf("tpmkey: unsupported algorithm %s", alg.Name)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	name, err := k.readName()
+	if err != nil {
+		return nil, err
+	}
+	handle := tpm2.AuthHandle{Handle: k.Handle, Name: name, Auth: tpm2.PasswordAuth(k.Auth)}
+
+	if len(message) <= maxBuffer {
+		rsp, err := tpm2.Hmac{
+			Handle:  handle,
+			Buffer:  tpm2.TPM2BMaxBuffer{Buffer: message},
+			HashAlg: hashAlg,
+		}.Execute(k.TPM)
+		if err != nil {
+			return nil, fmt.Errorf("tpmkey: hmac: %w", err)
+		}
+		return rsp.OutHMAC.Buffer, nil
+	}
+
+	start, err := tpm2.HmacStart{Handle: handle, HashAlg: hashAlg}.Execute(k.TPM)
+	if err != nil {
+		return nil, fmt.Errorf("tpmkey: hmac start: %w", err)
+	}
+	seq := tpm2.AuthHandle{Handle: start.SequenceHandle, Auth: tpm2.PasswordAuth(nil)}
+	for len(message) > maxBuffer {
+		_, err := tpm2.SequenceUpdate{
+			SequenceHandle: seq,
+			Buffer:         tpm2.TPM2BMaxBuffer{Buffer: message[:maxBuffer]},
+		}.Execute(k.TPM)
+		if err != nil {
+			tpm2.FlushContext{FlushHandle: start.SequenceHandle}.Execute(k.TPM)
+			return nil, fmt.Errorf("tpmkey: hmac update: %w", err)
+		}
+		message = message[maxBuffer:]
+	}
+	rsp, err := tpm2.SequenceComplete{
+		SequenceHandle: seq,
+		Buffer:         tpm2.TPM2BMaxBuffer{Buffer: message},
+		Hierarchy:      tpm2.TPMRHNull,
+	}.Execute(k.TPM)
+	if err != nil {
+		tpm2.FlushContext{FlushHandle: start.SequenceHandle}.Execute(k.TPM)
+		return nil, fmt.Errorf("tpmkey: hmac complete: %w", err)
+	}
+	return rsp.Result.Buffer, nil
+}
+
+func readName(tpm transport.TPM, handle tpm2.TPMHandle) (tpm2.TPM2BName, error) {
+	rsp, err := tpm2.ReadPublic{ObjectHandle: handle}.Execute(tpm)
+	if err != nil {
+		return tpm2.TPM2BName{}, fmt.Errorf("tpmkey: read public 0x%x: %w", uint32(handle), err)
+	}
+	return rsp.Name, nil
+}
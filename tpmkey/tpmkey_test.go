@@ -0,0 +1,58 @@
+package tpmkey_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/tpmkey"
+)
+
+var _ signedstrings.ExternalKey = (*tpmkey.Key)(nil)
+
+type brokenTPM struct{}
+
+func (brokenTPM) Send(input []byte) ([]byte, error) {
+	return nil, errors.New("no tpm")
+}
+
+func TestMAC_unsupportedAlgorithm(t *testing.T) {
+	k := &tpmkey.Key{TPM: brokenTPM{}, Handle: 0x81000001}
+	_, err := k.MAC(&signedstrings.Algorithm{Name: "Custom", Hash: sha256.New}, []byte("foo"))
+	if err == nil || err.Error() != "tpmkey: unsupported algorithm Custom" {
+		t.Errorf("MAC err = %v", err)
+	}
+}
+
+func TestMAC_tpmFailure(t *testing.T) {
+	k := &tpmkey.Key{TPM: brokenTPM{}, Handle: 0x81000001}
+	_, err := k.MAC(signedstrings.HS256, []byte("foo"))
+	if err == nil {
+		t.Fatal("MAC succeeded")
+	}
+}
+
+type countingTPM struct{ sends int }
+
+func (c *countingTPM) Send(input []byte) ([]byte, error) {
+	c.sends++
+	return nil, errors.New("tpm busy")
+}
+
+func TestMAC_retriesName(t *testing.T) {
+	tpm := &countingTPM{}
+	k := &tpmkey.Key{TPM: tpm, Handle: 0x81000001}
+	k.MAC(signedstrings.HS256, []byte("foo"))
+	k.MAC(signedstrings.HS256, []byte("foo"))
+	if tpm.sends != 2 {
+		t.Errorf("%d reads of the name, wanted a retry after the failure", tpm.sends)
+	}
+}
+
+func TestUnsealKeys_missingDevice(t *testing.T) {
+	_, err := tpmkey.UnsealKeys("/nonexistent/tpm", nil, 0x81000001)
+	if err == nil {
+		t.Fatal("UnsealKeys succeeded")
+	}
+}
@@ -0,0 +1,159 @@
+package signedstrings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// trailerKey names the trailer added by SignTrailer.
+const trailerKey = "Signed"
+
+// SignTrailer signs a commit-message-like text, e.g. release notes or
+// a change approval, by adding a git-style trailer:
+//
+//	Release 1.2
+//
+//	Fixes the frobnicator.
+//
+//	Approved-by: Jane
+//	Signed: a814acf20ffba3c8 1c54...7a1e
+//
+// The signature covers the text canonically (see VerifyTrailer), excluding
+// Signed trailers in the last paragraph, so several parties can sign the
+// same text.
+func (conf *Configuration) SignTrailer(msg string) (string, error) {
+	conf.sanityCheck()
+	lines := trailerLines(msg)
+	auth, err := conf.signDetached(conf.canonicalTrailerText(lines))
+	if err != nil {
+		return "", err
+	}
+	kid := conf.keyID(conf.algorithms()[0], 0)
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if start, end := lastParagraph(lines); !isTrailerBlock(lines[start:end]) {
+		lines = append(lines, "")
+	}
+	lines = append(lines, trailerKey+": "+kid+" "+auth)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// VerifyTrailer verifies a text produced by SignTrailer and returns the ID of
+// the key (see KeyID) that signed it. Line endings, trailing whitespace and
+// runs of blank lines do not affect verification. When there are several
+// Signed trailers, any one made with the configured keys is accepted.
+func (conf *Configuration) VerifyTrailer(msg string) (keyID string, err error) {
+	conf.sanityCheck()
+	lines := trailerLines(msg)
+	canonical := conf.canonicalTrailerText(lines)
+	err = Invalid
+	start, end := lastParagraph(lines)
+	if !isTrailerBlock(lines[start:end]) {
+		return "", err
+	}
+	for _, line := range lines[start:end] {
+		kid, auth, ok := conf.parseSignedTrailer(line)
+		if !ok || conf.containsSep(auth) {
+			continue
+		}
+		for _, prefix := range conf.prefixes() {
+			v, verr := conf.ValidateDetailed(conf.joinSig(prefix+canonical, auth))
+			if verr == nil && v.KeyID == kid {
+				return kid, nil
+			} else if verr != nil && !isValidationError(verr) {
+				return "", verr
+			} else if verr != nil {
+				err = verr
+			} else {
+				err = fmt.Errorf("%w: signed by %s, not %s", Invalid, v.KeyID, kid)
+			}
+		}
+	}
+	return "", err
+}
+
+// parseSignedTrailer parses a "Signed: key-id signature" line, where the
+// signature ends in a MAC of at least 16 bytes in one of the accepted
+// encodings. Other Signed lines, e.g. "Signed: John Smith", are ordinary text
+// covered by signatures.
+func (conf *Configuration) parseSignedTrailer(line string) (kid, auth string, ok bool) {
+	value, found := strings.CutPrefix(line, trailerKey+": ")
+	if !found {
+		return "", "", false
+	}
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return "", "", false
+	}
+	_, mac, _ := cutLast(fields[1], tagSep)
+	if mac == "" {
+		mac = fields[1]
+	}
+	for _, enc := range conf.macEncodings() {
+		if raw, err := enc.decode(mac); err == nil && len(raw) >= 16 {
+			return fields[0], fields[1], true
+		}
+	}
+	return "", "", false
+}
+
+// trailerLines splits msg into lines without trailing whitespace.
+func trailerLines(msg string) []string {
+	lines := strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return lines
+}
+
+// canonicalTrailerText joins lines, dropping Signed trailers, leading and
+// trailing blank lines, and collapsing runs of blank lines.
+func (conf *Configuration) canonicalTrailerText(lines []string) string {
+	start, end := lastParagraph(lines)
+	if !isTrailerBlock(lines[start:end]) {
+		start, end = 0, 0
+	}
+	var out []string
+	for i, line := range lines {
+		if _, _, ok := conf.parseSignedTrailer(line); ok && i >= start && i < end {
+			continue
+		}
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// lastParagraph returns the bounds of the last run of non-blank lines.
+func lastParagraph(lines []string) (start, end int) {
+	end = len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	start = end
+	for start > 0 && lines[start-1] != "" {
+		start--
+	}
+	return start, end
+}
+
+// isTrailerBlock returns whether every line looks like a git trailer.
+func isTrailerBlock(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	for _, line := range lines {
+		token, _, ok := strings.Cut(line, ": ")
+		if !ok || token == "" || strings.Trim(token, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return false
+		}
+	}
+	return true
+}
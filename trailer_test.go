@@ -0,0 +1,68 @@
+package signedstrings_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignTrailer() {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	signed := must(conf.SignTrailer("Release 1.2\n\nFixes the frobnicator.\n\nApproved-by: Jane\n"))
+	fmt.Print(signed)
+
+	print(conf.VerifyTrailer(strings.ReplaceAll(signed, "\n", "  \r\n\r\n")))
+	print(conf.VerifyTrailer(strings.Replace(signed, "Jane", "Mallory", 1)))
+	print(conf.VerifyTrailer(strings.Replace(signed, "a814acf20ffba3c8", "0000000000000000", 1)))
+
+	// Output: Release 1.2
+	//
+	// Fixes the frobnicator.
+	//
+	// Approved-by: Jane
+	// Signed: a814acf20ffba3c8 6fef8eb98908ebda0eff9afb35a8c702be38df78503d9155f5479f07146ed406
+	// a814acf20ffba3c8
+	// err: invalid signature
+	// err: invalid string: signed by a814acf20ffba3c8, not 0000000000000000
+}
+
+func TestSignTrailer_bodyParagraph(t *testing.T) {
+	conf := signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+	}
+	signed := must(conf.SignTrailer("Approval\n\nSigned: John Smith\nSigned: by hand, see the attached scan"))
+	if _, err := conf.VerifyTrailer(signed); err != nil {
+		t.Errorf("VerifyTrailer(%q) = %v", signed, err)
+	}
+	for _, tampered := range []string{
+		strings.Replace(signed, "by hand", "by fax", 1),
+		strings.Replace(signed, "John Smith", "Mallory Smith", 1),
+	} {
+		if _, err := conf.VerifyTrailer(tampered); err == nil {
+			t.Errorf("VerifyTrailer(%q) succeeded", tampered)
+		}
+	}
+	if _, err := conf.VerifyTrailer("Approval\n"); err == nil {
+		t.Error("VerifyTrailer succeeded without a trailer")
+	}
+}
+
+func TestSignTrailer_encodings(t *testing.T) {
+	for _, conf := range []*signedstrings.Configuration{
+		{Keys: [][]byte{exampleKey}, Sep: ".", SigEncodings: []string{"base64"}},
+		{Keys: [][]byte{exampleKey}, QR: true},
+		{Keys: [][]byte{exampleKey}, Algorithms: []string{"HS512"}, Timestamp: true},
+	} {
+		signed := must(conf.SignTrailer("RELEASE 1.2"))
+		if _, err := conf.VerifyTrailer(signed); err != nil {
+			t.Errorf("VerifyTrailer(%q) = %v", signed, err)
+		}
+		if _, err := conf.VerifyTrailer(strings.Replace(signed, "1.2", "1.3", 1)); err == nil {
+			t.Errorf("VerifyTrailer(tampered %q) succeeded", signed)
+		}
+	}
+}
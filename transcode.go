@@ -0,0 +1,43 @@
+package signedstrings
+
+import (
+	"context"
+	"errors"
+)
+
+// Transcode validates a signed string in any accepted format, with any
+// accepted prefix, algorithm and key, and re-signs its data in the current
+// one: the first of Versions, Prefixes, Algorithms and SigEncodings, and
+// the first key.
+// Use it for bulk migrations of stored strings, or to upgrade them lazily
+// on first use.
+//
+// A string that is already current is returned as is, so callers can
+// compare the result to tell whether to store it. The signing time of
+// timestamped strings is kept, so transcoding does not extend their
+// lifetime. Strings with caveats cannot be transcoded. This is the only way
+// to accept legacy strings, see LegacySHA1Until.
+func (conf *Configuration) Transcode(signed string) (string, error) {
+	v, err := conf.validateToken(context.Background(), signed, nil)
+	if err != nil && !errors.Is(err, NeedsReissue) {
+		return "", err
+	}
+	if conf.isCurrent(v) {
+		return signed, nil
+	}
+	prefix := conf.prefixes()[0]
+	if v.prefix == prefix {
+		prefix = v.matched // keep the concrete prefix for patterns
+	}
+	return conf.signAt(context.Background(), prefix, v.data, v.issued)
+}
+
+func (conf *Configuration) isCurrent(v validated) bool {
+	return v.version == conf.versions()[0] &&
+		v.prefix == conf.prefixes()[0] &&
+		v.alg == conf.algorithms()[0] &&
+		v.enc == conf.macEncoding() &&
+		v.keyIndex == 0 &&
+		!v.issued.IsZero() == conf.Timestamp &&
+		v.salted == conf.Salt
+}
@@ -0,0 +1,49 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_Transcode() {
+	newKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	old := signedstrings.Configuration{
+		Keys:     [][]byte{exampleKey},
+		Prefixes: []string{"OLD-"},
+	}
+	conf := signedstrings.Configuration{
+		Keys:       [][]byte{newKey, exampleKey},
+		Prefixes:   []string{"NEW-", "OLD-"},
+		Algorithms: []string{"HS512", "HS256"},
+		Versions:   []int{2, 1},
+	}
+
+	upgraded, err := conf.Transcode(old.Sign("foo"))
+	fmt.Println(upgraded, err)
+	again, err := conf.Transcode(upgraded)
+	fmt.Println(again == upgraded, err)
+	print(conf.Transcode("OLD-foo-d1d86203a92c9bffadedcd75ad6857ce7864b15ab0cfb826efcb6f361e0d0000"))
+
+	// Output: NEW-foo-!2.HS512.8f7487da171e91487af44145c4707a0ebb3507be86c08db8283fa2c24c71fbbfd2314ebd8f5e27b3cefce7e74a6e16036c71ecd572e16ee766e39a530dd8c6ca <nil>
+	// true <nil>
+	// err: invalid signature
+}
+
+func ExampleConfiguration_Transcode_timestamp() {
+	now := time.Unix(1700000000, 0)
+	old := signedstrings.Configuration{
+		Keys:      [][]byte{exampleKey},
+		Timestamp: true,
+		Now:       func() time.Time { return now },
+	}
+	conf := old
+	conf.Versions = []int{2, 1}
+	conf.Now = func() time.Time { return now.Add(time.Hour) }
+
+	fmt.Println(conf.Transcode(old.Sign("foo")))
+
+	// Output: foo-!2.@1700000000.25a53195b531be5dce231d06533504bb9a5bc7550f0b4f682c7961d42a9547d9 <nil>
+}
@@ -0,0 +1,147 @@
+package signedstrings
+
+import (
+	"net/url"
+	"strings"
+)
+
+// URLSignatureParam is the default query parameter that carries URL
+// signatures, see SignatureParam.
+const URLSignatureParam = "sig"
+
+// URLOption customizes SignURL and ValidateURL. Pass the same options to both.
+type URLOption func(o *urlOptions)
+
+type urlOptions struct {
+	sigParam  string
+	covered   map[string]bool // nil means all parameters
+	canonical bool
+}
+
+// SignatureParam puts the signature into the given query parameter instead
+// of URLSignatureParam.
+func SignatureParam(name string) URLOption {
+	return func(o *urlOptions) {
+		o.sigParam = name
+	}
+}
+
+// CoverParams limits the signature to the path and the given query
+// parameters, so that others (e.g. utm_source added by a mailer) can be
+// added or changed without breaking it. Covered parameters cannot be
+// added, removed or changed.
+func CoverParams(names ...string) URLOption {
+	return func(o *urlOptions) {
+		o.covered = make(map[string]bool, len(names))
+		for _, name := range names {
+			o.covered[name] = true
+		}
+	}
+}
+
+// CanonicalQuery makes the signature cover the query in the canonical form
+// of CanonicalizeQuery, so that proxies or clients that reorder parameters
+// or escape them differently do not break it.
+func CanonicalQuery() URLOption {
+	return func(o *urlOptions) {
+		o.canonical = true
+	}
+}
+
+// CanonicalizeQuery returns the canonical form of a raw query string:
+// parameters sorted by name, keeping the order of repeated ones, and
+// escaped the same way regardless of the original escaping, e.g.
+// "b=%7e&a=x+y" becomes "a=x+y&b=~". Fails if the query is malformed.
+func CanonicalizeQuery(rawQuery string) (string, error) {
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	return q.Encode(), nil
+}
+
+func newURLOptions(opts []URLOption) *urlOptions {
+	o := &urlOptions{sigParam: URLSignatureParam}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// SignURL appends a signature parameter covering the path and query of the
+// given URL, making it tamper-evident. The scheme and host are not covered,
+// so relative URLs work too.
+func (conf *Configuration) SignURL(rawURL string, opts ...URLOption) (string, error) {
+	o := newURLOptions(opts)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = filterParams(u.RawQuery, func(k string) bool { return k != o.sigParam })
+	data, err := o.signedData(u)
+	if err != nil {
+		return "", err
+	}
+	sig, err := conf.signDetached(data)
+	if err != nil {
+		return "", err
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&"
+	}
+	u.RawQuery += url.QueryEscape(o.sigParam) + "=" + url.QueryEscape(sig)
+	return u.String(), nil
+}
+
+// ValidateURL verifies the signature added by SignURL, e.g. of
+// r.URL.RequestURI() in a handler.
+func (conf *Configuration) ValidateURL(rawURL string, opts ...URLOption) error {
+	o := newURLOptions(opts)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Invalid
+	}
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil || len(q[o.sigParam]) != 1 {
+		return Invalid
+	}
+	sig := q.Get(o.sigParam)
+	u.RawQuery = filterParams(u.RawQuery, func(k string) bool { return k != o.sigParam })
+	data, err := o.signedData(u)
+	if err != nil {
+		return Invalid
+	}
+	return conf.validateDetached(data, sig)
+}
+
+func (o *urlOptions) signedData(u *url.URL) (string, error) {
+	query := u.RawQuery
+	if o.covered != nil {
+		query = filterParams(query, func(k string) bool { return o.covered[k] })
+	}
+	if o.canonical {
+		var err error
+		if query, err = CanonicalizeQuery(query); err != nil {
+			return "", err
+		}
+	}
+	return u.EscapedPath() + "?" + query, nil
+}
+
+// filterParams keeps the parameters of a raw query string whose unescaped
+// names satisfy keep, leaving the rest of the string intact.
+func filterParams(rawQuery string, keep func(name string) bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+	parts := strings.Split(rawQuery, "&")
+	kept := parts[:0]
+	for _, p := range parts {
+		k, _, _ := strings.Cut(p, "=")
+		if k, err := url.QueryUnescape(k); err == nil && !keep(k) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return strings.Join(kept, "&")
+}
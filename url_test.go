@@ -0,0 +1,54 @@
+package signedstrings_test
+
+import (
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleConfiguration_SignURL() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+
+	signed := must(conf.SignURL("https://example.com/download?file=report.pdf&user=42"))
+	fmt.Println(signed)
+	fmt.Println(conf.ValidateURL(signed))
+	fmt.Println(conf.ValidateURL(signed[len("https://example.com"):]))
+	fmt.Println(conf.ValidateURL(signed + "&admin=1"))
+	fmt.Println(conf.ValidateURL("/download?file=report.pdf&user=42"))
+
+	// Output: https://example.com/download?file=report.pdf&user=42&sig=a42ca3d0047494c4e8a5d40aa36616e8cc99de6699eae4526ba5cbb951abf5ee
+	// <nil>
+	// <nil>
+	// invalid signature
+	// invalid string
+}
+
+func ExampleCoverParams() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+	opts := []signedstrings.URLOption{signedstrings.CoverParams("file"), signedstrings.SignatureParam("s")}
+
+	signed := must(conf.SignURL("/download?file=report.pdf", opts...))
+	fmt.Println(signed)
+	fmt.Println(conf.ValidateURL(signed+"&utm_source=mail", opts...))
+	fmt.Println(conf.ValidateURL(signed+"&file=other.pdf", opts...))
+	fmt.Println(conf.ValidateURL(signed))
+
+	// Output: /download?file=report.pdf&s=21f7f4ac9e549aa10486fd1537589d09b5b9500dce8a8cb9774bf76fe5100619
+	// <nil>
+	// invalid signature
+	// invalid string
+}
+
+func ExampleCanonicalQuery() {
+	conf := &signedstrings.Configuration{Keys: [][]byte{exampleKey}}
+
+	signed := must(conf.SignURL("/search?q=a+b&page=2", signedstrings.CanonicalQuery()))
+	sig := signed[len("/search?q=a+b&page=2"):]
+	fmt.Println(conf.ValidateURL("/search?page=2&q=a%20b"+sig, signedstrings.CanonicalQuery()))
+	fmt.Println(conf.ValidateURL("/search?page=2&q=a%20b" + sig))
+	fmt.Println(signedstrings.CanonicalizeQuery("b=%7e&a=x+y&b=1"))
+
+	// Output: <nil>
+	// invalid signature
+	// a=x+y&b=~&b=1 <nil>
+}
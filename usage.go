@@ -0,0 +1,38 @@
+package signedstrings
+
+import "sync"
+
+// KeyUsage counts validations per key. Use its Record method as
+// Configuration.KeyUsed. The zero value is ready to use.
+type KeyUsage struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// Record counts a validation by the given key.
+func (u *KeyUsage) Record(keyID string, index int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.counts == nil {
+		u.counts = make(map[string]uint64)
+	}
+	u.counts[keyID]++
+}
+
+// Counts returns the number of validations served by each key ID so far.
+func (u *KeyUsage) Counts() map[string]uint64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	counts := make(map[string]uint64, len(u.counts))
+	for id, n := range u.counts {
+		counts[id] = n
+	}
+	return counts
+}
+
+// Reset zeroes the counters, e.g. at the start of a reporting interval.
+func (u *KeyUsage) Reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.counts = nil
+}
@@ -0,0 +1,31 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleKeyUsage() {
+	oldKey := must(hex.DecodeString("65ce238cb1b11d17a00c94c875394f500b05abd24c276a01691bdf9ce00d213c"))
+	old := signedstrings.Configuration{Keys: [][]byte{oldKey}}
+
+	var usage signedstrings.KeyUsage
+	conf := signedstrings.Configuration{
+		Keys:    [][]byte{exampleKey, oldKey},
+		KeyUsed: usage.Record,
+	}
+	conf.Validate(conf.Sign("foo"))
+	conf.Validate(conf.Sign("bar"))
+	conf.Validate(old.Sign("foo"))
+	conf.Validate("foo-1111111111111111111111111111111111111111111111111111111111111111")
+
+	counts := usage.Counts()
+	fmt.Println(counts[signedstrings.KeyID(exampleKey)], counts[signedstrings.KeyID(oldKey)])
+	usage.Reset()
+	fmt.Println(len(usage.Counts()))
+
+	// Output: 2 1
+	// 0
+}
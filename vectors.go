@@ -0,0 +1,117 @@
+package signedstrings
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// TestVector is a signed string with everything needed to validate it,
+// for checking compatibility of implementations in other languages.
+type TestVector struct {
+	Description string   `json:"description"`
+	Key         string   `json:"key"`                  // hex-encoded
+	PublicKey   string   `json:"public_key,omitempty"` // hex-encoded, asymmetric algorithms only
+	Prefix      string   `json:"prefix"`
+	Sep         string   `json:"sep"`
+	Algorithm   string   `json:"alg"`
+	Data        string   `json:"data"`
+	Token       string   `json:"token"`
+	Caveats     []string `json:"caveats,omitempty"` // see Attenuate
+	Valid       bool     `json:"valid"`
+}
+
+// vectorKey is the key of all test vectors: bytes 0 to 31.
+var vectorKey = func() []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}()
+
+// TestVectors returns test vectors covering the supported formats:
+// algorithms, prefixes, separators, timestamps, caveats, and tampered
+// strings that must be rejected. Vectors are deterministic.
+func TestVectors() []TestVector {
+	fixed := func() time.Time { return time.Unix(1700000000, 0) }
+	type variant struct {
+		desc    string
+		conf    Configuration
+		data    string
+		caveats []string
+		tamper  func(string) string
+	}
+	variants := []variant{
+		{desc: "default algorithm, no prefix", data: "foo"},
+		{desc: "empty data", data: ""},
+		{desc: "non-ASCII data", data: "héllo, 世界"},
+		{desc: "prefix", conf: Configuration{Prefixes: []string{"TOKEN-"}}, data: "foo"},
+		{desc: "custom separator", conf: Configuration{Sep: " :: "}, data: "foo-bar"},
+		{desc: "HS384", conf: Configuration{Algorithms: []string{"HS384"}}, data: "foo"},
+		{desc: "HS512", conf: Configuration{Algorithms: []string{"HS512"}}, data: "foo"},
+		{desc: "Ed25519", conf: Configuration{Algorithms: []string{"Ed25519"}}, data: "foo"},
+		{desc: "timestamp", conf: Configuration{Timestamp: true, Now: fixed}, data: "foo"},
+		{desc: "HS512 with timestamp", conf: Configuration{Algorithms: []string{"HS512"}, Timestamp: true, Now: fixed}, data: "foo"},
+		{desc: "caveats", data: "foo", caveats: []string{CaveatScope("read"), CaveatExpires(fixed())}},
+		{desc: "tampered data", data: "foo", tamper: func(s string) string { return "f0o" + s[3:] }},
+		{desc: "tampered signature", data: "foo", tamper: func(s string) string { return flipLast(s) }},
+		{desc: "truncated signature", data: "foo", tamper: func(s string) string { return s[:len(s)-2] }},
+		{desc: "algorithm tag swapped", conf: Configuration{Algorithms: []string{"HS512"}}, data: "foo", tamper: func(s string) string { return "foo-HS384." + s[10:] }},
+		{desc: "explicit default algorithm tag", data: "foo", tamper: func(s string) string { return "foo-HS256." + s[4:] }},
+		{desc: "data resembling a timestamp, separator removed", data: "foo-@1700000000.", tamper: func(s string) string { return s[:16] + s[17:] }},
+		{desc: "caveat removed", data: "foo", caveats: []string{CaveatScope("read")}, tamper: func(s string) string { return s[:4] + s[len(s)-64:] }},
+	}
+
+	var vectors []TestVector
+	for _, v := range variants {
+		conf := v.conf
+		conf.Keys = Keys{vectorKey}
+		signed := conf.Sign(v.data)
+		for _, c := range v.caveats {
+			var err error
+			if signed, err = conf.Attenuate(signed, c); err != nil {
+				panic(err)
+			}
+		}
+		vec := TestVector{
+			Description: v.desc,
+			Key:         hex.EncodeToString(vectorKey),
+			Prefix:      conf.prefixes()[0],
+			Sep:         conf.sep(),
+			Algorithm:   conf.algorithms()[0].Name,
+			Data:        v.data,
+			Token:       signed,
+			Caveats:     v.caveats,
+			Valid:       true,
+		}
+		if alg := conf.algorithms()[0]; alg.IsAsymmetric() {
+			pub, err := alg.Public(vectorKey)
+			if err != nil {
+				panic(err)
+			}
+			vec.PublicKey = hex.EncodeToString(pub)
+		}
+		if v.tamper != nil {
+			vec.Token, vec.Valid = v.tamper(signed), false
+		}
+		vectors = append(vectors, vec)
+	}
+	return vectors
+}
+
+// WriteTestVectors writes TestVectors as indented JSON.
+func WriteTestVectors(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(TestVectors())
+}
+
+// flipLast replaces the last hex digit of s with another one.
+func flipLast(s string) string {
+	if s[len(s)-1] == '0' {
+		return s[:len(s)-1] + "1"
+	}
+	return s[:len(s)-1] + "0"
+}
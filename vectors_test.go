@@ -0,0 +1,37 @@
+package signedstrings_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func TestTestVectors(t *testing.T) {
+	vectors := signedstrings.TestVectors()
+	if len(vectors) < 10 {
+		t.Fatalf("only %d vectors", len(vectors))
+	}
+	for _, v := range vectors {
+		conf := signedstrings.Configuration{
+			Keys:       [][]byte{must(hex.DecodeString(v.Key))},
+			Prefixes:   []string{v.Prefix},
+			Sep:        v.Sep,
+			Algorithms: []string{v.Algorithm},
+		}
+		data, err := conf.ValidateCaveats(v.Token, func(caveat string) error { return nil })
+		if v.Valid && (err != nil || data != v.Data) {
+			t.Errorf("%s: Validate(%q) = %q, %v, wanted %q", v.Description, v.Token, data, err, v.Data)
+		} else if !v.Valid && err == nil {
+			t.Errorf("%s: Validate(%q) = %q, wanted an error", v.Description, v.Token, data)
+		}
+	}
+}
+
+func TestTestVectors_hmac(t *testing.T) {
+	// computed independently: HMAC-SHA256 of "foo" with key 000102...1f
+	v := signedstrings.TestVectors()[0]
+	if want := "foo-5bf6643402d479ff01d3f0152a338ee42ca69db453208383c56c46549ef63d79"; v.Token != want {
+		t.Errorf("Token = %q, wanted %q", v.Token, want)
+	}
+}
@@ -0,0 +1,301 @@
+package webhooks
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// Scheme describes a vendor's webhook signature scheme: an HMAC of a message
+// built from the request, sent in a header. Register schemes to make them
+// available by name, so adding a provider needs no code at call sites.
+type Scheme struct {
+	// Name identifies the scheme in the registry, e.g. "slack".
+	Name string
+
+	// SignatureHeader carries the signature.
+	SignatureHeader string
+
+	// SignatureSep splits SignatureHeader into parts, e.g. "," for
+	// "t=1492774577,v1=5257a869...". Any of the signatures may match, which
+	// lets vendors rotate secrets. Empty means the header is a single part.
+	SignatureSep string
+
+	// SignaturePrefix is the prefix of signature parts, e.g. "v0=".
+	// Parts without it are ignored.
+	SignaturePrefix string
+
+	// TimestampHeader carries a Unix timestamp, checked against Tolerance.
+	// Empty for schemes without timestamps. If equal to SignatureHeader,
+	// the timestamp is the part starting with TimestampPrefix.
+	TimestampHeader string
+	TimestampPrefix string
+
+	// Tolerance is the maximum age of a request. Defaults to 5 minutes.
+	Tolerance time.Duration
+
+	// Hash is the hash function used with HMAC.
+	Hash func() hash.Hash
+
+	// Encoding is how signatures are encoded.
+	Encoding Encoding
+
+	// Template builds the signed message. It can contain {body}, {timestamp},
+	// {method}, {url} (the full URL, see Twilio.URL) and {header:Name}
+	// placeholders, e.g. "v0:{timestamp}:{body}".
+	Template string
+
+	// Message builds the signed message for schemes Template cannot express.
+	// Overrides Template.
+	Message func(r *http.Request, timestamp string, body []byte) ([]byte, error)
+}
+
+// Encoding is a signature encoding.
+type Encoding int
+
+const (
+	Hex Encoding = iota
+	Base64
+)
+
+func (enc Encoding) decode(s string) ([]byte, error) {
+	if enc == Base64 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return hex.DecodeString(s)
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = make(map[string]*Scheme)
+)
+
+// Built-in schemes, registered by default.
+var (
+	slackScheme = &Scheme{
+		Name:            "slack",
+		SignatureHeader: "X-Slack-Signature",
+		SignaturePrefix: "v0=",
+		TimestampHeader: "X-Slack-Request-Timestamp",
+		Hash:            sha256.New,
+		Template:        "v0:{timestamp}:{body}",
+	}
+	githubScheme = &Scheme{
+		Name:            "github",
+		SignatureHeader: "X-Hub-Signature-256",
+		SignaturePrefix: "sha256=",
+		Hash:            sha256.New,
+		Template:        "{body}",
+	}
+	shopifyScheme = &Scheme{
+		Name:            "shopify",
+		SignatureHeader: "X-Shopify-Hmac-Sha256",
+		Hash:            sha256.New,
+		Encoding:        Base64,
+		Template:        "{body}",
+	}
+	stripeScheme = &Scheme{
+		Name:            "stripe",
+		SignatureHeader: "Stripe-Signature",
+		SignatureSep:    ",",
+		SignaturePrefix: "v1=",
+		TimestampHeader: "Stripe-Signature",
+		TimestampPrefix: "t=",
+		Hash:            sha256.New,
+		Template:        "{timestamp}.{body}",
+	}
+	// twilioScheme uses the URL as received; use Twilio behind proxies.
+	twilioScheme = &Scheme{
+		Name:            "twilio",
+		SignatureHeader: "X-Twilio-Signature",
+		Hash:            sha1.New,
+		Encoding:        Base64,
+		Message:         twilioMessage,
+	}
+)
+
+func init() {
+	Register(slackScheme)
+	Register(githubScheme)
+	Register(shopifyScheme)
+	Register(stripeScheme)
+	Register(twilioScheme)
+}
+
+// Register makes the scheme available via Lookup. Panics if the scheme is
+// incomplete or its name is already taken.
+func Register(s *Scheme) {
+	if s.Name == "" || s.SignatureHeader == "" || s.Hash == nil || s.Template == "" && s.Message == nil {
+		panic("webhooks: incomplete scheme " + s.Name)
+	}
+	if s.Message == nil {
+		if err := checkTemplate(s.Template); err != "" {
+			panic("webhooks: scheme " + s.Name + ": " + err)
+		}
+	}
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if schemes[s.Name] != nil {
+		panic("webhooks: duplicate scheme " + s.Name)
+	}
+	schemes[s.Name] = s
+}
+
+// Lookup returns the registered scheme with the given name, or nil if there
+// is none.
+func Lookup(name string) *Scheme {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	return schemes[name]
+}
+
+// Verifier verifies webhooks signed according to Scheme.
+type Verifier struct {
+	// Conf holds the vendor secrets in Keys.
+	Conf *signedstrings.Configuration
+
+	Scheme *Scheme
+
+	// MaxBodySize limits how much of the body is read. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Verify checks the signature of the request. The body is read and replaced
+// with an in-memory copy.
+func (v *Verifier) Verify(r *http.Request) error {
+	keys, s := keys(v.Conf), v.Scheme
+	if s == nil {
+		panic("webhooks: Verifier.Scheme not set")
+	}
+
+	var sigs [][]byte
+	var ts string
+	for _, part := range splitHeader(r.Header.Get(s.SignatureHeader), s.SignatureSep) {
+		if s.TimestampHeader == s.SignatureHeader {
+			if t, ok := strings.CutPrefix(part, s.TimestampPrefix); ok {
+				ts = t
+				continue
+			}
+		}
+		encoded, ok := strings.CutPrefix(part, s.SignaturePrefix)
+		if !ok {
+			continue
+		}
+		sig, err := s.Encoding.decode(encoded)
+		if err != nil {
+			return signedstrings.Invalid
+		}
+		sigs = append(sigs, sig)
+	}
+	if len(sigs) == 0 {
+		return signedstrings.Invalid
+	}
+
+	if s.TimestampHeader != "" {
+		if s.TimestampHeader != s.SignatureHeader {
+			ts = r.Header.Get(s.TimestampHeader)
+		}
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return signedstrings.Invalid
+		}
+		if err := checkTimestamp(v.Conf, time.Unix(unix, 0), s.Tolerance); err != nil {
+			return err
+		}
+	}
+
+	body, err := readBody(r, v.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	var message []byte
+	if s.Message != nil {
+		message, err = s.Message(r, ts, body)
+		if err != nil {
+			return err
+		}
+	} else {
+		message = expandTemplate(s.Template, r, ts, body)
+	}
+
+	for _, sig := range sigs {
+		if matchAny(keys, sig, func(key []byte) []byte {
+			return mac(s.Hash, key, message)
+		}) {
+			return nil
+		}
+	}
+	return signedstrings.InvalidSig
+}
+
+// Middleware rejects requests that fail Verify with 401 Unauthorized.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return middleware(v, next)
+}
+
+func splitHeader(value, sep string) []string {
+	if value == "" {
+		return nil
+	} else if sep == "" {
+		return []string{value}
+	}
+	parts := strings.Split(value, sep)
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// checkTemplate returns a description of the problem with tpl, if any.
+func checkTemplate(tpl string) string {
+	for {
+		start := strings.IndexByte(tpl, '{')
+		if start < 0 {
+			return ""
+		}
+		end := strings.IndexByte(tpl[start:], '}')
+		if end < 0 {
+			return "unterminated placeholder in template"
+		}
+		switch name := tpl[start+1 : start+end]; {
+		case name == "body", name == "timestamp", name == "method", name == "url", strings.HasPrefix(name, "header:"):
+		default:
+			return "unknown placeholder {" + name + "}"
+		}
+		tpl = tpl[start+end+1:]
+	}
+}
+
+func expandTemplate(tpl string, r *http.Request, ts string, body []byte) []byte {
+	var buf []byte
+	for {
+		start := strings.IndexByte(tpl, '{')
+		if start < 0 {
+			return append(buf, tpl...)
+		}
+		end := start + strings.IndexByte(tpl[start:], '}')
+		buf = append(buf, tpl[:start]...)
+		switch name := tpl[start+1 : end]; name {
+		case "body":
+			buf = append(buf, body...)
+		case "timestamp":
+			buf = append(buf, ts...)
+		case "method":
+			buf = append(buf, r.Method...)
+		case "url":
+			buf = append(buf, requestURL(r)...)
+		default:
+			buf = append(buf, r.Header.Get(strings.TrimPrefix(name, "header:"))...)
+		}
+		tpl = tpl[end+1:]
+	}
+}
@@ -0,0 +1,111 @@
+package webhooks_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/webhooks"
+)
+
+func hexMAC(key, message string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestVerifier_github(t *testing.T) {
+	const secret, body = "It's a Secret to Everybody", "Hello, World!"
+	v := &webhooks.Verifier{
+		Conf:   &signedstrings.Configuration{Keys: [][]byte{[]byte(secret)}},
+		Scheme: webhooks.Lookup("github"),
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	// From GitHub's documentation.
+	r.Header.Set("X-Hub-Signature-256", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+	if err := v.Verify(r); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+}
+
+func TestVerifier_stripe(t *testing.T) {
+	const secret, body = "whsec_test_secret", `{"id":"evt_1"}`
+	now := time.Unix(1700000000, 0)
+	v := &webhooks.Verifier{
+		Conf: &signedstrings.Configuration{
+			Keys: [][]byte{[]byte(secret)},
+			Now:  func() time.Time { return now },
+		},
+		Scheme: webhooks.Lookup("stripe"),
+	}
+	sig := hexMAC(secret, "1700000000."+body)
+	tests := []struct {
+		header string
+		want   error
+	}{
+		{"t=1700000000,v1=" + sig, nil},
+		{"t=1700000000,v1=" + hexMAC("whsec_old", "1700000000."+body) + ",v1=" + sig, nil},
+		{"t=1700000000,v0=" + sig, signedstrings.Invalid},
+		{"t=1700000001,v1=" + sig, signedstrings.InvalidSig},
+		{"t=1699990000,v1=" + hexMAC(secret, "1699990000."+body), signedstrings.StaleRequest},
+		{"v1=" + sig, signedstrings.Invalid},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		r.Header.Set("Stripe-Signature", tt.header)
+		if err := v.Verify(r); err != tt.want {
+			t.Errorf("Verify(%s) = %v, wanted %v", tt.header, err, tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	webhooks.Register(&webhooks.Scheme{
+		Name:            "acme",
+		SignatureHeader: "X-Acme-Signature",
+		Hash:            sha512.New,
+		Template:        "{method} {url}\n{header:X-Acme-Delivery}\n{body}",
+	})
+	v := &webhooks.Verifier{
+		Conf:   &signedstrings.Configuration{Keys: [][]byte{[]byte("acme secret")}},
+		Scheme: webhooks.Lookup("acme"),
+	}
+
+	h := hmac.New(sha512.New, []byte("acme secret"))
+	fmt.Fprintf(h, "POST http://example.com/hooks?x=1\n42\n{}")
+	r := httptest.NewRequest("POST", "http://example.com/hooks?x=1", strings.NewReader("{}"))
+	r.Header.Set("X-Acme-Delivery", "42")
+	r.Header.Set("X-Acme-Signature", hex.EncodeToString(h.Sum(nil)))
+	if err := v.Verify(r); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+
+	assertPanic(t, "webhooks: duplicate scheme slack", func() {
+		webhooks.Register(&webhooks.Scheme{Name: "slack", SignatureHeader: "X", Hash: sha256.New, Template: "{body}"})
+	})
+	assertPanic(t, "webhooks: scheme bad: unknown placeholder {bogus}", func() {
+		webhooks.Register(&webhooks.Scheme{Name: "bad", SignatureHeader: "X", Hash: sha256.New, Template: "{bogus}"})
+	})
+	assertPanic(t, "webhooks: incomplete scheme bad", func() {
+		webhooks.Register(&webhooks.Scheme{Name: "bad", SignatureHeader: "X"})
+	})
+}
+
+func assertPanic(t testing.TB, expected string, f func()) {
+	t.Helper()
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic %q, got none", expected)
+		} else if msg := fmt.Sprint(e); msg != expected {
+			t.Errorf("expected panic %q, got %q", expected, msg)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// Shopify verifies Shopify signatures: X-Shopify-Hmac-Sha256 on webhooks
+// (a base64 HMAC-SHA256 of the body), the hmac parameter on OAuth and admin
+// redirects, and the signature parameter on app proxy requests.
+type Shopify struct {
+	// Conf holds Shopify app client secrets in Keys.
+	Conf *signedstrings.Configuration
+
+	// MaxBodySize limits how much of the body is read. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Verify checks the signature of a webhook request. The body is read and
+// replaced with an in-memory copy.
+func (s *Shopify) Verify(r *http.Request) error {
+	return (&Verifier{Conf: s.Conf, Scheme: shopifyScheme, MaxBodySize: s.MaxBodySize}).Verify(r)
+}
+
+// VerifyQuery checks the hmac parameter of OAuth and admin requests: a hex
+// HMAC-SHA256 of the other parameters, sorted and joined with &.
+func (s *Shopify) VerifyQuery(query url.Values) error {
+	return s.verifyQuery(query, "hmac", "&")
+}
+
+// VerifyProxy checks the signature parameter of app proxy requests: a hex
+// HMAC-SHA256 of the other parameters, sorted and concatenated, with
+// multiple values joined by commas.
+func (s *Shopify) VerifyProxy(query url.Values) error {
+	return s.verifyQuery(query, "signature", "")
+}
+
+func (s *Shopify) verifyQuery(query url.Values, param, sep string) error {
+	keys := keys(s.Conf)
+	sig, err := hex.DecodeString(query.Get(param))
+	if err != nil || len(sig) == 0 {
+		return signedstrings.Invalid
+	}
+
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		if name == "hmac" || name == "signature" {
+			continue
+		}
+		pairs = append(pairs, name+"="+strings.Join(values, ","))
+	}
+	sort.Strings(pairs)
+	message := []byte(strings.Join(pairs, sep))
+
+	if !matchAny(keys, sig, func(key []byte) []byte {
+		return mac(sha256.New, key, message)
+	}) {
+		return signedstrings.InvalidSig
+	}
+	return nil
+}
+
+// Middleware rejects webhook requests that fail Verify with 401 Unauthorized.
+func (s *Shopify) Middleware(next http.Handler) http.Handler {
+	return middleware(s, next)
+}
@@ -0,0 +1,61 @@
+package webhooks_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/webhooks"
+)
+
+func TestShopify(t *testing.T) {
+	const secret = "hush"
+	s := &webhooks.Shopify{Conf: &signedstrings.Configuration{
+		Keys: [][]byte{[]byte(secret)},
+	}}
+
+	const body = `{"id":820982911946154508,"email":"jon@example.com"}`
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(body))
+	sig := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	r := httptest.NewRequest("POST", "/webhooks/orders", strings.NewReader(body))
+	r.Header.Set("X-Shopify-Hmac-Sha256", sig)
+	if err := s.Verify(r); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+	r = httptest.NewRequest("POST", "/webhooks/orders", strings.NewReader(body+" "))
+	r.Header.Set("X-Shopify-Hmac-Sha256", sig)
+	if err := s.Verify(r); err != signedstrings.InvalidSig {
+		t.Errorf("Verify(tampered) = %v", err)
+	}
+}
+
+// The OAuth example is from Shopify's documentation.
+func TestShopify_query(t *testing.T) {
+	s := &webhooks.Shopify{Conf: &signedstrings.Configuration{
+		Keys: [][]byte{[]byte("hush")},
+	}}
+	tests := []struct {
+		query  string
+		verify func(url.Values) error
+		want   error
+	}{
+		{"code=0907a61c0c8d55e99db179b68161bc00&hmac=700e2dadb827fcc8609e9d5ce208b2e9cdaab9df07390d2cbca10d7c328fc4bf&shop=some-shop.myshopify.com&state=0.6784241404160823&timestamp=1337178173", s.VerifyQuery, nil},
+		{"code=0907a61c0c8d55e99db179b68161bc00&hmac=700e2dadb827fcc8609e9d5ce208b2e9cdaab9df07390d2cbca10d7c328fc4bf&shop=evil-shop.myshopify.com&state=0.6784241404160823&timestamp=1337178173", s.VerifyQuery, signedstrings.InvalidSig},
+		{"code=0907a61c0c8d55e99db179b68161bc00&shop=some-shop.myshopify.com", s.VerifyQuery, signedstrings.Invalid},
+		{"extra=1&extra=2&shop=shop-name.myshopify.com&logged_in_customer_id=1&path_prefix=%2Fapps%2Fawesome_reviews&timestamp=1317327555&signature=4c68c8624d737112c91818c11017d24d334b524cb5c2b8ba08daa056f7395ddb", s.VerifyProxy, nil},
+		{"extra=1&extra=3&shop=shop-name.myshopify.com&logged_in_customer_id=1&path_prefix=%2Fapps%2Fawesome_reviews&timestamp=1317327555&signature=4c68c8624d737112c91818c11017d24d334b524cb5c2b8ba08daa056f7395ddb", s.VerifyProxy, signedstrings.InvalidSig},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		if err := tt.verify(q); err != tt.want {
+			t.Errorf("%s: %v, wanted %v", tt.query, err, tt.want)
+		}
+	}
+}
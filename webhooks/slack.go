@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// Slack verifies Slack request signatures (the v0 scheme): an HMAC-SHA256
+// of "v0:timestamp:body" sent as X-Slack-Signature: v0=<hex>, with the
+// timestamp in X-Slack-Request-Timestamp.
+type Slack struct {
+	// Conf holds Slack signing secrets in Keys.
+	Conf *signedstrings.Configuration
+
+	// Tolerance is the maximum age of a request. Defaults to 5 minutes,
+	// as recommended by Slack.
+	Tolerance time.Duration
+
+	// MaxBodySize limits how much of the body is read. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Verify checks the signature of the request. The body is read and replaced
+// with an in-memory copy.
+func (s *Slack) Verify(r *http.Request) error {
+	scheme := *slackScheme
+	scheme.Tolerance = s.Tolerance
+	return (&Verifier{Conf: s.Conf, Scheme: &scheme, MaxBodySize: s.MaxBodySize}).Verify(r)
+}
+
+// Middleware rejects requests that fail Verify with 401 Unauthorized.
+func (s *Slack) Middleware(next http.Handler) http.Handler {
+	return middleware(s, next)
+}
@@ -0,0 +1,76 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/webhooks"
+)
+
+// slackBody and slackSecret are the example from Slack's documentation.
+const (
+	slackSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+	slackBody   = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+)
+
+func newSlackRequest(ts, sig string) *http.Request {
+	r := httptest.NewRequest("POST", "/slack/commands", strings.NewReader(slackBody))
+	r.Header.Set("X-Slack-Request-Timestamp", ts)
+	r.Header.Set("X-Slack-Signature", sig)
+	return r
+}
+
+func TestSlack(t *testing.T) {
+	s := &webhooks.Slack{Conf: &signedstrings.Configuration{
+		Keys: [][]byte{[]byte("0000000000000000000000000000new0"), []byte(slackSecret)},
+		Now:  func() time.Time { return time.Unix(1531420618+60, 0) },
+	}}
+	const sig = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+
+	tests := []struct {
+		ts, sig string
+		want    error
+	}{
+		{"1531420618", sig, nil},
+		{"1531420619", sig, signedstrings.InvalidSig},
+		{"1531420618", "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b504", signedstrings.InvalidSig},
+		{"1531420618", "v1=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503", signedstrings.Invalid},
+		{"1531420618", "v0=zz", signedstrings.Invalid},
+		{"", sig, signedstrings.Invalid},
+		{"1531410618", sig, signedstrings.StaleRequest},
+	}
+	for _, tt := range tests {
+		if err := s.Verify(newSlackRequest(tt.ts, tt.sig)); err != tt.want {
+			t.Errorf("Verify(%s, %s) = %v, wanted %v", tt.ts, tt.sig, err, tt.want)
+		}
+	}
+}
+
+func TestSlack_Middleware(t *testing.T) {
+	s := &webhooks.Slack{Conf: &signedstrings.Configuration{
+		Keys: [][]byte{[]byte(slackSecret)},
+		Now:  func() time.Time { return time.Unix(1531420618, 0) },
+	}}
+	h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Write([]byte(r.PostForm.Get("user_name")))
+	}))
+
+	w := httptest.NewRecorder()
+	r := newSlackRequest("1531420618", "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503")
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "roadrunner" {
+		t.Errorf("valid request: %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, newSlackRequest("1531420618", "v0=00"))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("invalid request: %d", w.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// Standard Webhooks schemes, registered by default. Svix uses the same
+// scheme with svix- headers.
+var (
+	standardScheme = &Scheme{
+		Name:            "standard-webhooks",
+		SignatureHeader: "Webhook-Signature",
+		SignatureSep:    " ",
+		SignaturePrefix: "v1,",
+		TimestampHeader: "Webhook-Timestamp",
+		Hash:            sha256.New,
+		Encoding:        Base64,
+		Template:        "{header:Webhook-Id}.{timestamp}.{body}",
+	}
+	svixScheme = &Scheme{
+		Name:            "svix",
+		SignatureHeader: "Svix-Signature",
+		SignatureSep:    " ",
+		SignaturePrefix: "v1,",
+		TimestampHeader: "Svix-Timestamp",
+		Hash:            sha256.New,
+		Encoding:        Base64,
+		Template:        "{header:Svix-Id}.{timestamp}.{body}",
+	}
+)
+
+func init() {
+	Register(standardScheme)
+	Register(svixScheme)
+}
+
+const secretPrefix = "whsec_"
+
+// ParseSecret decodes a whsec_ secret into a key for Configuration.Keys.
+func ParseSecret(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(s, secretPrefix))
+}
+
+// FormatSecret encodes a key as a whsec_ secret to share with receivers.
+func FormatSecret(key []byte) string {
+	return secretPrefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// StandardWebhooks signs and verifies webhooks per the Standard Webhooks
+// specification, which is also used by Svix: a base64 HMAC-SHA256 of
+// "id.timestamp.body", sent as "v1,<signature>" in Webhook-Signature
+// alongside Webhook-Id and Webhook-Timestamp headers.
+type StandardWebhooks struct {
+	// Conf holds the secrets in Keys, decoded with ParseSecret. The first key
+	// signs, all are accepted.
+	Conf *signedstrings.Configuration
+
+	// Tolerance is the maximum age of a request. Defaults to 5 minutes.
+	Tolerance time.Duration
+
+	// MaxBodySize limits how much of the body is read. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Sign returns the Webhook-Signature header value for the given message.
+func (sw *StandardWebhooks) Sign(id string, t time.Time, body []byte) string {
+	sig := mac(sha256.New, keys(sw.Conf)[0], []byte(id+"."+strconv.FormatInt(t.Unix(), 10)+"."), body)
+	return "v1," + base64.StdEncoding.EncodeToString(sig)
+}
+
+// SignRequest sets the Webhook-Id, Webhook-Timestamp and Webhook-Signature
+// headers of an outgoing request. The body is read and replaced with an
+// in-memory copy.
+func (sw *StandardWebhooks) SignRequest(r *http.Request, id string) error {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	t := now(sw.Conf)
+	r.Header.Set("Webhook-Id", id)
+	r.Header.Set("Webhook-Timestamp", strconv.FormatInt(t.Unix(), 10))
+	r.Header.Set("Webhook-Signature", sw.Sign(id, t, body))
+	return nil
+}
+
+// Verify checks the signature of the request, accepting both webhook- and
+// svix- headers. The body is read and replaced with an in-memory copy.
+func (sw *StandardWebhooks) Verify(r *http.Request) error {
+	scheme := *standardScheme
+	if r.Header.Get(standardScheme.SignatureHeader) == "" && r.Header.Get(svixScheme.SignatureHeader) != "" {
+		scheme = *svixScheme
+	}
+	scheme.Tolerance = sw.Tolerance
+	return (&Verifier{Conf: sw.Conf, Scheme: &scheme, MaxBodySize: sw.MaxBodySize}).Verify(r)
+}
+
+// Middleware rejects requests that fail Verify with 401 Unauthorized.
+func (sw *StandardWebhooks) Middleware(next http.Handler) http.Handler {
+	return middleware(sw, next)
+}
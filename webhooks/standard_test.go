@@ -0,0 +1,77 @@
+package webhooks_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/webhooks"
+)
+
+// The example from the Svix documentation.
+const (
+	svixSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	svixID     = "msg_p5jXN8AQM9LWM0D4loKWxJek"
+	svixBody   = `{"test": 2432232314}`
+	svixSig    = "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="
+)
+
+func newStandardWebhooks(t *testing.T, secrets ...string) *webhooks.StandardWebhooks {
+	conf := &signedstrings.Configuration{Now: func() time.Time { return time.Unix(1614265330, 0) }}
+	for _, s := range secrets {
+		key, err := webhooks.ParseSecret(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.Keys = append(conf.Keys, key)
+	}
+	return &webhooks.StandardWebhooks{Conf: conf}
+}
+
+func TestStandardWebhooks_Sign(t *testing.T) {
+	sw := newStandardWebhooks(t, svixSecret)
+	if sig := sw.Sign(svixID, time.Unix(1614265330, 0), []byte(svixBody)); sig != svixSig {
+		t.Errorf("Sign = %s, wanted %s", sig, svixSig)
+	}
+	if s := webhooks.FormatSecret(sw.Conf.Keys[0]); s != svixSecret {
+		t.Errorf("FormatSecret = %s", s)
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(svixBody))
+	if err := sw.SignRequest(r, svixID); err != nil {
+		t.Fatal(err)
+	}
+	if sig := r.Header.Get("Webhook-Signature"); sig != svixSig {
+		t.Errorf("Webhook-Signature = %s", sig)
+	}
+	if err := sw.Verify(r); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+}
+
+func TestStandardWebhooks_Verify(t *testing.T) {
+	sw := newStandardWebhooks(t, "whsec_AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", svixSecret)
+	tests := []struct {
+		prefix, id, ts, sig string
+		want                error
+	}{
+		{"Webhook-", svixID, "1614265330", svixSig, nil},
+		{"Svix-", svixID, "1614265330", svixSig, nil},
+		{"Webhook-", svixID, "1614265330", "v1,AAAA " + svixSig, nil},
+		{"Webhook-", "msg_other", "1614265330", svixSig, signedstrings.InvalidSig},
+		{"Webhook-", svixID, "1614265331", svixSig, signedstrings.InvalidSig},
+		{"Webhook-", svixID, "1614265330", "v1a," + svixSig[3:], signedstrings.Invalid},
+		{"Webhook-", svixID, "1614260000", svixSig, signedstrings.StaleRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(svixBody))
+		r.Header.Set(tt.prefix+"Id", tt.id)
+		r.Header.Set(tt.prefix+"Timestamp", tt.ts)
+		r.Header.Set(tt.prefix+"Signature", tt.sig)
+		if err := sw.Verify(r); err != tt.want {
+			t.Errorf("Verify(%s %s %s %s) = %v, wanted %v", tt.prefix, tt.id, tt.ts, tt.sig, err, tt.want)
+		}
+	}
+}
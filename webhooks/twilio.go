@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+// Twilio verifies X-Twilio-Signature: a base64 HMAC-SHA1 of the full request
+// URL followed by the sorted POST parameters (names and values concatenated).
+// Requests with non-form bodies carry a bodySHA256 query parameter instead,
+// which is checked against the body.
+type Twilio struct {
+	// Conf holds Twilio auth tokens in Keys.
+	Conf *signedstrings.Configuration
+
+	// URL returns the URL Twilio has requested, exactly as configured in
+	// Twilio. Defaults to the scheme, Host and RequestURI of the request,
+	// using X-Forwarded-Proto behind TLS-terminating proxies. Set it when
+	// a proxy rewrites the host or path.
+	URL func(r *http.Request) string
+
+	// MaxBodySize limits how much of the body is read. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// Verify checks the signature of the request. The body is read and replaced
+// with an in-memory copy.
+func (tw *Twilio) Verify(r *http.Request) error {
+	keys := keys(tw.Conf)
+	sig, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Twilio-Signature"))
+	if err != nil || len(sig) == 0 {
+		return signedstrings.Invalid
+	}
+	body, err := readBody(r, tw.MaxBodySize)
+	if err != nil {
+		return err
+	}
+
+	var rawURL string
+	if tw.URL != nil {
+		rawURL = tw.URL(r)
+	} else {
+		rawURL = requestURL(r)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	params, err := twilioParams(r, u, body)
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range twilioURLVariants(u) {
+		if matchAny(keys, sig, func(key []byte) []byte {
+			return mac(sha1.New, key, []byte(variant), []byte(params))
+		}) {
+			return nil
+		}
+	}
+	return signedstrings.InvalidSig
+}
+
+// Middleware rejects requests that fail Verify with 401 Unauthorized.
+func (tw *Twilio) Middleware(next http.Handler) http.Handler {
+	return middleware(tw, next)
+}
+
+// twilioMessage implements Scheme.Message for the registered twilio scheme.
+func twilioMessage(r *http.Request, timestamp string, body []byte) ([]byte, error) {
+	rawURL := requestURL(r)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	params, err := twilioParams(r, u, body)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(rawURL + params), nil
+}
+
+// twilioParams returns the signed form parameters, or checks the body
+// against bodySHA256 for non-form requests.
+func twilioParams(r *http.Request, u *url.URL, body []byte) (string, error) {
+	if bodyHash := u.Query().Get("bodySHA256"); bodyHash != "" {
+		digest := sha256.Sum256(body)
+		if !strings.EqualFold(bodyHash, hex.EncodeToString(digest[:])) {
+			return "", signedstrings.InvalidSig
+		}
+		return "", nil
+	} else if !isForm(r) {
+		return "", nil
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", signedstrings.Invalid
+	}
+
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf strings.Builder
+	for _, name := range names {
+		values := append([]string(nil), form[name]...)
+		sort.Strings(values)
+		for _, v := range values {
+			buf.WriteString(name)
+			buf.WriteString(v)
+		}
+	}
+	return buf.String(), nil
+}
+
+// twilioURLVariants returns the URL as is, and with the default port added
+// or removed, because Twilio's treatment of ports has varied over time.
+func twilioURLVariants(u *url.URL) []string {
+	variants := []string{u.String()}
+	alt := *u
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "https":
+			alt.Host += ":443"
+		case "http":
+			alt.Host += ":80"
+		default:
+			return variants
+		}
+	} else if u.Scheme == "https" && u.Port() == "443" || u.Scheme == "http" && u.Port() == "80" {
+		alt.Host = u.Hostname()
+	} else {
+		return variants
+	}
+	return append(variants, alt.String())
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+func isForm(r *http.Request) bool {
+	ct, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	return strings.TrimSpace(ct) == "application/x-www-form-urlencoded"
+}
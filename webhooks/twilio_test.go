@@ -0,0 +1,53 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/signedstrings"
+	"github.com/andreyvit/signedstrings/webhooks"
+)
+
+// The examples below are Twilio's own test vectors.
+const twilioToken = "12345"
+
+func newTwilioRequest(url, contentType, body, sig string) *http.Request {
+	r := httptest.NewRequest("POST", url, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("X-Twilio-Signature", sig)
+	return r
+}
+
+func TestTwilio(t *testing.T) {
+	tw := &webhooks.Twilio{Conf: &signedstrings.Configuration{
+		Keys: [][]byte{[]byte(twilioToken)},
+	}}
+	const (
+		form = "application/x-www-form-urlencoded"
+		url  = "https://mycompany.com/myapp.php?foo=1&bar=2"
+		body = "CallSid=CA1234567890ABCDE&Caller=%2B14158675310&Digits=1234&From=%2B14158675310&To=%2B18005551212"
+		sig  = "GvWf1cFY/Q7PnoempGyD5oXAezc="
+	)
+	tests := []struct {
+		name               string
+		url, ct, body, sig string
+		want               error
+	}{
+		{"form", url, form, body, sig, nil},
+		{"port", "https://mycompany.com:443/myapp.php?foo=1&bar=2", form, body, sig, nil},
+		{"tampered param", url, form, strings.Replace(body, "1234&", "1235&", 1), sig, signedstrings.InvalidSig},
+		{"tampered URL", "https://mycompany.com/myapp.php?foo=2&bar=2", form, body, sig, signedstrings.InvalidSig},
+		{"unsigned", url, form, body, "", signedstrings.Invalid},
+		{"JSON", "https://mycompany.com/myapp.php?foo=1&bar=2&bodySHA256=0a1ff7634d9ab3b95db5c9a2dfe9416e41502b283a80c7cf19632632f96e6620", "application/json", `{"property": "value", "boolean": true}`, "a9nBmqA0ju/hNViExpshrM61xv4=", nil},
+		{"tampered JSON", "https://mycompany.com/myapp.php?foo=1&bar=2&bodySHA256=0a1ff7634d9ab3b95db5c9a2dfe9416e41502b283a80c7cf19632632f96e6620", "application/json", `{"property": "value", "boolean": false}`, "a9nBmqA0ju/hNViExpshrM61xv4=", signedstrings.InvalidSig},
+	}
+	for _, tt := range tests {
+		r := newTwilioRequest(tt.url, tt.ct, tt.body, tt.sig)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		if err := tw.Verify(r); err != tt.want {
+			t.Errorf("%s: Verify = %v, wanted %v", tt.name, err, tt.want)
+		}
+	}
+}
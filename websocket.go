@@ -0,0 +1,108 @@
+package signedstrings
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// WebSocketTickets mints and checks tickets authorizing WebSocket upgrades.
+// Browsers cannot set headers on WebSocket connections, so the page fetches
+// a ticket with an authenticated request, and passes it in the query of the
+// upgrade URL, where it may end up in logs. Tickets are therefore bound to
+// a user and a channel, short-lived and single-use.
+type WebSocketTickets struct {
+	Conf *Configuration
+
+	// TTL is the lifetime of tickets. Defaults to 30 seconds.
+	TTL time.Duration
+
+	// Nonces remembers used tickets. Required; use a shared store when
+	// running several instances.
+	Nonces NonceStore
+
+	// Param is the query parameter carrying the ticket. Defaults to "ticket".
+	Param string
+}
+
+// wsTicketType marks ticket claims, so that other claims signed with the
+// same keys are not accepted as tickets.
+const wsTicketType = "ws-ticket"
+
+type wsTicket struct {
+	Claims
+	Type    string `json:"typ"`
+	ID      string `json:"jti"`
+	UserID  string `json:"uid"`
+	Channel string `json:"ch"`
+}
+
+// Issue returns a ticket authorizing the given user to connect to the
+// given channel.
+func (t *WebSocketTickets) Issue(userID, channel string) (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return t.Conf.IssueClaims(&wsTicket{
+		Claims:  Claims{ExpiresAt: t.Conf.now().Add(t.ttl()).Unix()},
+		Type:    wsTicketType,
+		ID:      hex.EncodeToString(id[:]),
+		UserID:  userID,
+		Channel: channel,
+	})
+}
+
+// Verify checks a ticket for the given channel, uses it up, and returns the
+// user ID it was issued for. A ticket presented again fails with Replayed.
+func (t *WebSocketTickets) Verify(ticket, channel string) (string, error) {
+	return t.VerifyContext(context.Background(), ticket, channel)
+}
+
+// VerifyContext is like Verify, but passes ctx to Nonces if it implements
+// ContextNonceStore.
+func (t *WebSocketTickets) VerifyContext(ctx context.Context, ticket, channel string) (string, error) {
+	if t.Nonces == nil {
+		panic("signedstrings: WebSocketTickets.Nonces is not set")
+	}
+	var claims wsTicket
+	err := t.Conf.VerifyClaimsContext(ctx, ticket, &claims, RequireClaim("typ", wsTicketType), RequireClaim("ch", channel))
+	if err != nil {
+		return "", err
+	} else if claims.ID == "" {
+		return "", Invalid
+	}
+	if fresh, err := useNonce(ctx, t.Nonces, claims.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return "", err
+	} else if !fresh {
+		return "", Replayed
+	}
+	return claims.UserID, nil
+}
+
+// VerifyRequest is VerifyContext for the ticket in the query of an upgrade
+// request, with the context of the request.
+// Call it in the upgrade handler before accepting the connection.
+func (t *WebSocketTickets) VerifyRequest(r *http.Request, channel string) (string, error) {
+	ticket := r.URL.Query().Get(t.param())
+	if ticket == "" {
+		return "", Invalid
+	}
+	return t.VerifyContext(r.Context(), ticket, channel)
+}
+
+func (t *WebSocketTickets) ttl() time.Duration {
+	if t.TTL > 0 {
+		return t.TTL
+	}
+	return 30 * time.Second
+}
+
+func (t *WebSocketTickets) param() string {
+	if t.Param != "" {
+		return t.Param
+	}
+	return "ticket"
+}
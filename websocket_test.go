@@ -0,0 +1,59 @@
+package signedstrings_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/signedstrings"
+)
+
+func ExampleWebSocketTickets() {
+	tickets := &signedstrings.WebSocketTickets{
+		Conf:   &signedstrings.Configuration{Keys: [][]byte{exampleKey}},
+		Nonces: &signedstrings.MemoryNonceStore{},
+	}
+
+	// in an authenticated API handler
+	ticket, _ := tickets.Issue("user42", "chat:1")
+
+	// in the upgrade handler
+	r := httptest.NewRequest("GET", "/ws/chat/1?ticket="+url.QueryEscape(ticket), nil)
+	fmt.Println(tickets.VerifyRequest(r, "chat:1"))
+	print(tickets.VerifyRequest(r, "chat:1"))
+
+	// Output: user42 <nil>
+	// err: replayed message
+}
+
+func TestWebSocketTickets(t *testing.T) {
+	now := time.Now()
+	conf := &signedstrings.Configuration{
+		Keys: [][]byte{exampleKey},
+		Now:  func() time.Time { return now },
+	}
+	tickets := &signedstrings.WebSocketTickets{Conf: conf, Nonces: &signedstrings.MemoryNonceStore{}}
+
+	ticket, err := tickets.Issue("user42", "chat:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tickets.Verify(ticket, "chat:2"); !errors.Is(err, signedstrings.UnacceptableClaims) {
+		t.Errorf("Verify for another channel err = %v", err)
+	}
+	session, _ := conf.IssueMap(map[string]any{"uid": "user42", "ch": "chat:1", "jti": "x"}, time.Minute)
+	if _, err := tickets.Verify(session, "chat:1"); !errors.Is(err, signedstrings.UnacceptableClaims) {
+		t.Errorf("Verify of non-ticket claims err = %v", err)
+	}
+	if _, err := tickets.VerifyRequest(httptest.NewRequest("GET", "/ws", nil), "chat:1"); err != signedstrings.Invalid {
+		t.Errorf("VerifyRequest without ticket err = %v", err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, err := tickets.Verify(ticket, "chat:1"); !errors.Is(err, signedstrings.Expired) {
+		t.Errorf("Verify of expired ticket err = %v", err)
+	}
+}